@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -19,6 +20,7 @@ type gotel struct {
 	tracer         trace.Tracer
 	tracerProvider trace.TracerProvider
 	propagator     propagation.TextMapPropagator
+	resource       *resource.Resource
 }
 
 func (g *gotel) DefaultTracer() trace.Tracer {
@@ -2,8 +2,15 @@ package gotel
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -14,29 +21,44 @@ import (
 func NewOtelWithJaegerExporter(serviceName string, opt OtelWithJaegerOption) Gotel {
 	ctx := context.Background()
 
-	traceOpts := []otlptracehttp.Option{
-		otlptracehttp.WithEndpoint(opt.Endpoint),
+	traceOpts, err := newJaegerClientOptions(opt)
+	if err != nil {
+		panic(err)
 	}
 
-	if !opt.IsSecure {
-		traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
+	client := otlptracehttp.NewClient(traceOpts...)
+
+	// Wrap the client so undeliverable batches survive a collector outage
+	if opt.Spool.Dir != "" {
+		spool, err := newSpoolClient(client, opt.Spool)
+		if err != nil {
+			panic(err)
+		}
+
+		client = spool
 	}
 
-	exporter, err := otlptracehttp.New(ctx, traceOpts...)
+	exporter, err := otlptrace.New(ctx, client)
 	if err != nil {
 		panic(err)
 	}
 
-	traceProvider := sdktrace.NewTracerProvider(
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	)
+
+	providerOpts := []sdktrace.TracerProviderOption{
 		sdktrace.WithSampler(sdktrace.AlwaysSample()),
 		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(
-			resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceName(serviceName),
-			),
-		),
-	)
+		sdktrace.WithResource(res),
+	}
+
+	if opt.EnableProfilingLabels {
+		providerOpts = append(providerOpts, sdktrace.WithSpanProcessor(NewProfilingSpanProcessor()))
+	}
+
+	traceProvider := sdktrace.NewTracerProvider(providerOpts...)
 
 	otel.SetTracerProvider(traceProvider)
 
@@ -51,6 +73,7 @@ func NewOtelWithJaegerExporter(serviceName string, opt OtelWithJaegerOption) Got
 		tracer:         traceProvider.Tracer(serviceName),
 		tracerProvider: traceProvider,
 		propagator:     prop,
+		resource:       res,
 	}
 
 	// Set to global for easy to use every where
@@ -58,3 +81,91 @@ func NewOtelWithJaegerExporter(serviceName string, opt OtelWithJaegerOption) Got
 
 	return gotel
 }
+
+// newJaegerClientOptions returns the options of the OTLP HTTP client exporting
+// to the collector of opt.
+func newJaegerClientOptions(opt OtelWithJaegerOption) ([]otlptracehttp.Option, error) {
+	traceOpts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(opt.Endpoint),
+	}
+
+	// Certificates are only of use over TLS, they turn it on
+	if opt.CACertFile != "" || opt.ClientCertFile != "" || opt.ClientKeyFile != "" {
+		tlsConfig, err := newTLSConfig(opt)
+		if err != nil {
+			return nil, err
+		}
+
+		traceOpts = append(traceOpts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	} else if !opt.IsSecure {
+		traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
+	}
+
+	if len(opt.Headers) > 0 {
+		traceOpts = append(traceOpts, otlptracehttp.WithHeaders(opt.Headers))
+	}
+
+	if opt.URLPath != "" {
+		traceOpts = append(traceOpts, otlptracehttp.WithURLPath(opt.URLPath))
+	}
+
+	if opt.Compression {
+		traceOpts = append(traceOpts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+
+	if opt.Timeout > 0 {
+		traceOpts = append(traceOpts, otlptracehttp.WithTimeout(opt.Timeout))
+	}
+
+	if opt.Retry.Disable {
+		traceOpts = append(traceOpts, otlptracehttp.WithRetry(otlptracehttp.RetryConfig{Enabled: false}))
+	} else if opt.Retry != (RetryOption{}) {
+		traceOpts = append(traceOpts, otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
+			Enabled:         true,
+			InitialInterval: durationOrDefault(opt.Retry.InitialInterval, 5*time.Second),
+			MaxInterval:     durationOrDefault(opt.Retry.MaxInterval, 30*time.Second),
+			MaxElapsedTime:  durationOrDefault(opt.Retry.MaxElapsedTime, time.Minute),
+		}))
+	}
+
+	return traceOpts, nil
+}
+
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+
+	return d
+}
+
+// newTLSConfig builds the TLS configuration for a collector that requires a
+// private CA and/or client certificates.
+func newTLSConfig(opt OtelWithJaegerOption) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if opt.CACertFile != "" {
+		pem, err := os.ReadFile(opt.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("CA bundle contains no valid certificate")
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if opt.ClientCertFile != "" || opt.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opt.ClientCertFile, opt.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,271 @@
+package gotel
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// testCA issues certificates for the collector and its clients.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gotel test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse CA: %v", err)
+	}
+
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns a certificate signed by the CA along with its PEM encoding.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (tls.Certificate, []byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("key pair: %v", err)
+	}
+
+	return pair, certPEM, keyPEM
+}
+
+func writeFile(t *testing.T, name string, b []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+
+	return path
+}
+
+// exportRequest is what the mTLS collector saw of an export.
+type exportRequest struct {
+	path        string
+	tenant      string
+	encoding    string
+	clientCerts int
+	spans       []string
+}
+
+// newMTLSCollector returns a collector that only accepts clients with a
+// certificate signed by ca, and reports each export on the returned channel.
+func newMTLSCollector(t *testing.T, ca *testCA) (*httptest.Server, <-chan exportRequest) {
+	t.Helper()
+
+	requests := make(chan exportRequest, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := exportRequest{
+			path:        r.URL.Path,
+			tenant:      r.Header.Get("X-Tenant"),
+			encoding:    r.Header.Get("Content-Encoding"),
+			clientCerts: len(r.TLS.PeerCertificates),
+		}
+
+		var body io.Reader = r.Body
+		if got.encoding == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gz
+		}
+
+		b, _ := io.ReadAll(body)
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(b, req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		for _, rs := range req.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				for _, span := range ss.GetSpans() {
+					got.spans = append(got.spans, span.GetName())
+				}
+			}
+		}
+
+		requests <- got
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serverCert, _, _ := ca.issue(t, "collector", x509.ExtKeyUsageServerAuth)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func TestJaegerClientOptionsMTLS(t *testing.T) {
+	ca := newTestCA(t)
+	server, requests := newMTLSCollector(t, ca)
+	_, clientCert, clientKey := ca.issue(t, "service", x509.ExtKeyUsageClientAuth)
+
+	// IsSecure is left unset: the certificates alone turn TLS on
+	opts, err := newJaegerClientOptions(OtelWithJaegerOption{
+		Endpoint:       strings.TrimPrefix(server.URL, "https://"),
+		Retry:          RetryOption{Disable: true},
+		CACertFile:     writeFile(t, "ca.pem", ca.pem),
+		ClientCertFile: writeFile(t, "client.pem", clientCert),
+		ClientKeyFile:  writeFile(t, "client-key.pem", clientKey),
+		Headers:        map[string]string{"X-Tenant": "payments"},
+		URLPath:        "/otlp/v1/traces",
+		Compression:    true,
+	})
+	if err != nil {
+		t.Fatalf("newJaegerClientOptions: %v", err)
+	}
+
+	client := otlptracehttp.NewClient(opts...)
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { client.Stop(ctx) })
+
+	if err := client.UploadTraces(ctx, batch("charge")); err != nil {
+		t.Fatalf("UploadTraces: %v", err)
+	}
+
+	got := <-requests
+	if got.path != "/otlp/v1/traces" {
+		t.Errorf("path = %q, want %q", got.path, "/otlp/v1/traces")
+	}
+	if got.tenant != "payments" {
+		t.Errorf("X-Tenant = %q, want %q", got.tenant, "payments")
+	}
+	if got.encoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", got.encoding)
+	}
+	if got.clientCerts == 0 {
+		t.Error("collector saw no client certificate")
+	}
+	if len(got.spans) != 1 || got.spans[0] != "charge" {
+		t.Errorf("spans = %v, want [charge]", got.spans)
+	}
+}
+
+func TestJaegerClientOptionsRequiresClientCert(t *testing.T) {
+	ca := newTestCA(t)
+	server, _ := newMTLSCollector(t, ca)
+
+	opts, err := newJaegerClientOptions(OtelWithJaegerOption{
+		Endpoint:   strings.TrimPrefix(server.URL, "https://"),
+		Retry:      RetryOption{Disable: true},
+		CACertFile: writeFile(t, "ca.pem", ca.pem),
+	})
+	if err != nil {
+		t.Fatalf("newJaegerClientOptions: %v", err)
+	}
+
+	client := otlptracehttp.NewClient(opts...)
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { client.Stop(ctx) })
+
+	if err := client.UploadTraces(ctx, batch("charge")); err == nil {
+		t.Error("UploadTraces without a client certificate succeeded")
+	}
+}
+
+func TestJaegerClientOptionsBadCertificate(t *testing.T) {
+	_, err := newJaegerClientOptions(OtelWithJaegerOption{
+		Endpoint:   "localhost:4318",
+		CACertFile: writeFile(t, "ca.pem", []byte("not a certificate")),
+	})
+	if err == nil {
+		t.Error("newJaegerClientOptions accepted an invalid CA bundle")
+	}
+}
@@ -15,16 +15,18 @@ func NewOtelWithStdoutExporter(serviceName string) Gotel {
 		panic(err)
 	}
 
-	traceProvider := sdktrace.NewTracerProvider(
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	)
+
+	providerOpts := []sdktrace.TracerProviderOption{
 		sdktrace.WithSampler(sdktrace.AlwaysSample()),
 		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(
-			resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceName(serviceName),
-			),
-		),
-	)
+		sdktrace.WithResource(res),
+	}
+
+	traceProvider := sdktrace.NewTracerProvider(providerOpts...)
 
 	otel.SetTracerProvider(traceProvider)
 
@@ -39,6 +41,7 @@ func NewOtelWithStdoutExporter(serviceName string) Gotel {
 		tracer:         traceProvider.Tracer(serviceName),
 		tracerProvider: traceProvider,
 		propagator:     prop,
+		resource:       res,
 	}
 
 	// Set global variable
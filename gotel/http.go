@@ -0,0 +1,154 @@
+package gotel
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceparentHeader is the standard W3C Trace-Context HTTP header.
+	TraceparentHeader = "traceparent"
+
+	// TraceIDHeader is the default response header carrying the bare trace id.
+	TraceIDHeader = "X-Trace-Id"
+)
+
+// TraceID returns the trace id of the span in ctx, formatted the same way as
+// the trace.id field written by logger.TraceContext. It returns an empty
+// string when ctx carries no valid span.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
+
+// SpanID returns the span id of the span in ctx, or an empty string when ctx
+// carries no valid span.
+func SpanID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasSpanID() {
+		return ""
+	}
+
+	return spanContext.SpanID().String()
+}
+
+// Traceparent returns the W3C traceparent value of the span in ctx, or an
+// empty string when ctx carries no valid span.
+// https://www.w3.org/TR/trace-context/#traceparent-header
+func Traceparent(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+
+	const version = 0
+	return fmt.Sprintf("%02x-%s-%s-%s", version, spanContext.TraceID(), spanContext.SpanID(), spanContext.TraceFlags())
+}
+
+// InjectResponseHeaders writes the traceparent and X-Trace-Id headers of the
+// span in ctx into header. It does nothing when ctx carries no valid span.
+func InjectResponseHeaders(ctx context.Context, header http.Header) {
+	injectResponseHeaders(ctx, header, HTTPMiddlewareOption{
+		EchoTraceparent: true,
+		EchoTraceID:     true,
+	})
+}
+
+// NewHTTPMiddleware returns a middleware that continues the trace propagated
+// by the caller, starts a server span for every request and optionally echoes
+// the trace identifiers on the response.
+func NewHTTPMiddleware(opt HTTPMiddlewareOption) func(http.Handler) http.Handler {
+	spanName := opt.SpanNameFormatter
+	if spanName == nil {
+		spanName = func(r *http.Request) string {
+			return r.Method
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := DefaultTracer().Start(ctx, spanName(r),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPMethod(r.Method),
+					semconv.HTTPTarget(r.URL.RequestURI()),
+				),
+			)
+			defer span.End()
+
+			// Headers must be set before the handler writes the status code
+			injectResponseHeaders(ctx, w.Header(), opt)
+
+			rw := &statusResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			span.SetAttributes(semconv.HTTPStatusCode(rw.statusCode))
+			if rw.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rw.statusCode))
+			}
+		})
+	}
+}
+
+func injectResponseHeaders(ctx context.Context, header http.Header, opt HTTPMiddlewareOption) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+
+	if opt.EchoTraceparent {
+		header.Set(TraceparentHeader, Traceparent(ctx))
+	}
+
+	if opt.EchoTraceID {
+		key := opt.TraceIDHeader
+		if key == "" {
+			key = TraceIDHeader
+		}
+
+		header.Set(key, TraceID(ctx))
+	}
+}
+
+// statusResponseWriter records the status code written by the handler.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets upgrade handlers, such as WebSocket, take over the connection.
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("gotel: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+
+	return hijacker.Hijack()
+}
+
+func (w *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
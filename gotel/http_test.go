@@ -0,0 +1,138 @@
+package gotel
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHTTPMiddlewareEchoesTraceHeaders(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("b03b1bba60aa5e3e8c2ee0ce141b0ad8")
+	spanID, _ := trace.SpanIDFromHex("e4fa02ad9b3bde14")
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	var handlerTraceID string
+	mw := NewHTTPMiddleware(HTTPMiddlewareOption{EchoTraceparent: true, EchoTraceID: true})
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerTraceID = TraceID(r.Context())
+	}))
+
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil).WithContext(ctx)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	// Without an SDK provider the span is non recording, but keeps the trace id
+	if handlerTraceID != traceID.String() {
+		t.Fatalf("handler trace id = %q, want %q", handlerTraceID, traceID)
+	}
+	if got := rec.Header().Get(TraceIDHeader); got != traceID.String() {
+		t.Errorf("%s = %q, want %q", TraceIDHeader, got, traceID)
+	}
+	if got := rec.Header().Get(TraceparentHeader); got == "" {
+		t.Errorf("%s is not set", TraceparentHeader)
+	}
+}
+
+func TestHTTPMiddlewareForwardsFlush(t *testing.T) {
+	var flushable bool
+	mw := NewHTTPMiddleware(HTTPMiddlewareOption{})
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var flusher http.Flusher
+		flusher, flushable = w.(http.Flusher)
+		if flushable {
+			w.Write([]byte("data: 1\n\n"))
+			flusher.Flush()
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if !flushable {
+		t.Fatal("response writer does not implement http.Flusher")
+	}
+	if !rec.Flushed {
+		t.Error("flush was not forwarded to the underlying writer")
+	}
+}
+
+func TestHTTPMiddlewareSpanName(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	NewHTTPMiddleware(HTTPMiddlewareOption{})(handler).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/123", nil))
+
+	named := NewHTTPMiddleware(HTTPMiddlewareOption{SpanNameFormatter: func(r *http.Request) string {
+		return r.Method + " /orders/{id}"
+	}})
+	named(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders/456", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	if got := spans[0].Name(); got != http.MethodGet {
+		t.Errorf("span name = %q, want the method only", got)
+	}
+	if got := spans[1].Name(); got != "POST /orders/{id}" {
+		t.Errorf("span name = %q, want the formatted name", got)
+	}
+}
+
+func TestHTTPMiddlewareForwardsHijack(t *testing.T) {
+	mw := NewHTTPMiddleware(HTTPMiddlewareOption{})
+	server := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "not a hijacker", http.StatusInternalServerError)
+			return
+		}
+
+		conn, buf, err := hijacker.Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\nhijacked\n")
+		buf.Flush()
+	})))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "GET /ws HTTP/1.1\r\nHost: example\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status = %d, want 101", resp.StatusCode)
+	}
+}
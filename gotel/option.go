@@ -1,6 +1,67 @@
 package gotel
 
+import (
+	"net/http"
+	"time"
+)
+
 type OtelWithJaegerOption struct {
 	Endpoint string
 	IsSecure bool
+	Retry    RetryOption
+	Spool    SpoolOption
+
+	// CACertFile is a PEM bundle used instead of the system roots to verify the
+	// collector. Setting it or the client certificate turns TLS on, whatever
+	// IsSecure is.
+	CACertFile string
+	// ClientCertFile and ClientKeyFile hold the PEM client certificate for mTLS.
+	ClientCertFile string
+	ClientKeyFile  string
+	// Headers are sent with every export, e.g. API keys or bearer tokens.
+	Headers map[string]string
+	// URLPath overrides the default /v1/traces path.
+	URLPath string
+	// Compression enables gzip compression of the exported payload.
+	Compression bool
+	// Timeout bounds every export request. Default: 10s.
+	Timeout time.Duration
+
+	// EnableProfilingLabels sets pprof goroutine labels with the trace and span ids when spans start.
+	EnableProfilingLabels bool
+}
+
+// RetryOption configures the exponential backoff used when an export fails.
+// Zero durations fall back to the exporter defaults (5s, 30s and 1m).
+type RetryOption struct {
+	// Disable turns retrying off, a failed export is dropped (or spooled) at once.
+	Disable         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// SpoolOption configures the on-disk spool for batches that could not be
+// delivered after all retries. Spooling is enabled when Dir is set.
+type SpoolOption struct {
+	// Dir is the directory holding the spooled batches.
+	Dir string
+	// MaxBytes bounds the spool size, the oldest batches are dropped first. Default: 64MB.
+	MaxBytes int64
+	// DrainInterval is how often the spool is replayed to the collector. Default: 30s.
+	DrainInterval time.Duration
+}
+
+// HTTPMiddlewareOption configures the HTTP server middleware.
+type HTTPMiddlewareOption struct {
+	// EchoTraceparent writes the W3C traceparent header on every response.
+	EchoTraceparent bool
+	// EchoTraceID writes the bare trace id on every response.
+	EchoTraceID bool
+	// TraceIDHeader overrides the header used by EchoTraceID. Default: X-Trace-Id.
+	TraceIDHeader string
+	// SpanNameFormatter names the server span of a request, such as
+	// "GET /orders/{id}" from the route template. Default: the HTTP method, as
+	// raw paths holding ids would make every span name unique.
+	SpanNameFormatter func(r *http.Request) string
 }
@@ -0,0 +1,146 @@
+package gotel
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ProfileLabelTraceID is the pprof label holding the trace id of the running span.
+	ProfileLabelTraceID = "trace_id"
+	// ProfileLabelSpanID is the pprof label holding the span id of the running span.
+	ProfileLabelSpanID = "span_id"
+)
+
+// Profile is a pprof encoded profile tagged with the service resource.
+type Profile struct {
+	Type      string
+	Labels    map[string]string
+	StartTime time.Time
+	Duration  time.Duration
+	Data      []byte
+}
+
+// profilingSpanProcessor labels the goroutine starting a span with its trace
+// and span ids, so CPU samples can be correlated with the span.
+type profilingSpanProcessor struct{}
+
+// NewProfilingSpanProcessor returns a span processor that sets pprof goroutine
+// labels when spans start. Goroutine labels cannot be reset from OnEnd, since a
+// span may end on another goroutine than the one that started it, so the
+// labels stay until the goroutine starts another span. Use WithProfileLabels
+// to scope them to a function instead.
+func NewProfilingSpanProcessor() sdktrace.SpanProcessor {
+	return &profilingSpanProcessor{}
+}
+
+func (p *profilingSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	spanContext := s.SpanContext()
+	if !spanContext.IsValid() {
+		return
+	}
+
+	pprof.SetGoroutineLabels(withSpanLabels(parent, spanContext))
+}
+
+func (p *profilingSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {}
+
+func (p *profilingSpanProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (p *profilingSpanProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// WithProfileLabels calls fn with the trace and span ids of the span in ctx
+// set as pprof labels, like pprof.Do, and gives the goroutine back its labels
+// when fn returns. Goroutines started by fn inherit the labels.
+func WithProfileLabels(ctx context.Context, fn func(context.Context)) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		fn(ctx)
+		return
+	}
+
+	pprof.Do(ctx, pprof.Labels(
+		ProfileLabelTraceID, spanContext.TraceID().String(),
+		ProfileLabelSpanID, spanContext.SpanID().String(),
+	), fn)
+}
+
+func withSpanLabels(ctx context.Context, spanContext trace.SpanContext) context.Context {
+	return pprof.WithLabels(ctx, pprof.Labels(
+		ProfileLabelTraceID, spanContext.TraceID().String(),
+		ProfileLabelSpanID, spanContext.SpanID().String(),
+	))
+}
+
+// CaptureCPUProfile records a CPU profile for the given duration, or until ctx
+// is done, and tags it with the service resource and the span in ctx.
+func CaptureCPUProfile(ctx context.Context, duration time.Duration) (Profile, error) {
+	var buf bytes.Buffer
+
+	start := time.Now()
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return Profile{}, err
+	}
+
+	timer := time.NewTimer(duration)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+
+	pprof.StopCPUProfile()
+
+	return Profile{
+		Type:      "cpu",
+		Labels:    profileLabels(ctx),
+		StartTime: start,
+		Duration:  time.Since(start),
+		Data:      buf.Bytes(),
+	}, nil
+}
+
+// CaptureHeapProfile records a heap profile tagged with the service resource
+// and the span in ctx.
+func CaptureHeapProfile(ctx context.Context) (Profile, error) {
+	var buf bytes.Buffer
+
+	start := time.Now()
+	if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+		return Profile{}, err
+	}
+
+	return Profile{
+		Type:      "heap",
+		Labels:    profileLabels(ctx),
+		StartTime: start,
+		Data:      buf.Bytes(),
+	}, nil
+}
+
+func profileLabels(ctx context.Context) map[string]string {
+	labels := make(map[string]string)
+
+	if res := DefaultResource(); res != nil {
+		for _, attr := range res.Attributes() {
+			labels[string(attr.Key)] = attr.Value.Emit()
+		}
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		labels[ProfileLabelTraceID] = spanContext.TraceID().String()
+		labels[ProfileLabelSpanID] = spanContext.SpanID().String()
+	}
+
+	return labels
+}
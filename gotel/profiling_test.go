@@ -0,0 +1,112 @@
+package gotel
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// goroutineLabels returns the pprof labels of the goroutines whose stack goes
+// through fn, as printed by the goroutine profile.
+func goroutineLabels(t *testing.T, fn string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		t.Fatalf("goroutine profile: %v", err)
+	}
+
+	for _, record := range strings.Split(buf.String(), "\n\n") {
+		if !strings.Contains(record, "."+fn+"(") && !strings.Contains(record, "."+fn+"+") {
+			continue
+		}
+
+		for _, line := range strings.Split(record, "\n") {
+			if labels, ok := strings.CutPrefix(line, "# labels: "); ok {
+				return labels
+			}
+		}
+		return ""
+	}
+
+	t.Fatalf("no goroutine running %s", fn)
+	return ""
+}
+
+// endOnOtherGoroutine labels itself as the ender, ends span and waits for
+// release, so the labels of the goroutine that ended it can be inspected.
+func endOnOtherGoroutine(span trace.Span, ended, release chan struct{}) {
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels("role", "ender")))
+	span.End()
+	close(ended)
+	<-release
+}
+
+func TestProfilingSpanProcessorLabelsStartingGoroutine(t *testing.T) {
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewProfilingSpanProcessor()))
+	defer provider.Shutdown(context.Background())
+	defer pprof.SetGoroutineLabels(context.Background())
+
+	tracer := provider.Tracer("test")
+	self := "TestProfilingSpanProcessorLabelsStartingGoroutine"
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	defer parent.End()
+
+	parentID := parent.SpanContext().SpanID().String()
+	if labels := goroutineLabels(t, self); !strings.Contains(labels, parentID) {
+		t.Fatalf("goroutine labels = %s, want the parent span", labels)
+	}
+
+	_, child := tracer.Start(ctx, "child")
+	childID := child.SpanContext().SpanID().String()
+	if labels := goroutineLabels(t, self); !strings.Contains(labels, childID) {
+		t.Fatalf("goroutine labels = %s, want the child span", labels)
+	}
+
+	// Ending the span elsewhere leaves the labels of the ending goroutine alone
+	ended, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	go endOnOtherGoroutine(child, ended, release)
+	<-ended
+
+	if labels := goroutineLabels(t, "endOnOtherGoroutine"); labels != `{"role":"ender"}` {
+		t.Errorf("goroutine ending the span has labels %s, want its own", labels)
+	}
+}
+
+func TestWithProfileLabels(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "charge")
+	defer span.End()
+
+	self := "TestWithProfileLabels"
+	spanID := span.SpanContext().SpanID().String()
+
+	called := false
+	WithProfileLabels(ctx, func(ctx context.Context) {
+		called = true
+
+		if labels := goroutineLabels(t, self); !strings.Contains(labels, spanID) {
+			t.Errorf("goroutine labels = %s, want the span", labels)
+		}
+		if got, _ := pprof.Label(ctx, ProfileLabelSpanID); got != spanID {
+			t.Errorf("ctx label %s = %q, want %q", ProfileLabelSpanID, got, spanID)
+		}
+	})
+
+	if !called {
+		t.Fatal("fn was not called")
+	}
+	if labels := goroutineLabels(t, self); labels != "" {
+		t.Errorf("goroutine labels = %s after fn returned, want none", labels)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -49,3 +50,11 @@ func GetTextMapPropagator() propagation.TextMapPropagator {
 	}
 	return _gotel.GetTextMapPropagator()
 }
+
+func DefaultResource() *resource.Resource {
+	g, ok := _gotel.(*gotel)
+	if !ok {
+		return nil
+	}
+	return g.resource
+}
@@ -0,0 +1,292 @@
+package gotel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultSpoolMaxBytes      = 64 << 20
+	defaultSpoolDrainInterval = 30 * time.Second
+	spoolFileExt              = ".otlp"
+)
+
+// spoolClient wraps an OTLP client and writes batches that could not be
+// delivered to disk, replaying them once the collector is reachable again.
+type spoolClient struct {
+	otlptrace.Client
+
+	dir           string
+	maxBytes      int64
+	drainInterval time.Duration
+
+	mu   sync.Mutex // guards writes and size enforcement of the spool dir
+	seq  atomic.Uint64
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+
+	// drainCtx is cancelled by Stop, so a replay stuck in retries ends with it
+	drainCtx    context.Context
+	cancelDrain context.CancelFunc
+	stopOnce    sync.Once
+}
+
+func newSpoolClient(client otlptrace.Client, opt SpoolOption) (*spoolClient, error) {
+	if err := os.MkdirAll(opt.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+
+	drainCtx, cancelDrain := context.WithCancel(context.Background())
+
+	s := &spoolClient{
+		Client:        client,
+		dir:           opt.Dir,
+		maxBytes:      opt.MaxBytes,
+		drainInterval: opt.DrainInterval,
+		kick:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		drainCtx:      drainCtx,
+		cancelDrain:   cancelDrain,
+	}
+
+	if s.maxBytes <= 0 {
+		s.maxBytes = defaultSpoolMaxBytes
+	}
+
+	if s.drainInterval <= 0 {
+		s.drainInterval = defaultSpoolDrainInterval
+	}
+
+	return s, nil
+}
+
+func (s *spoolClient) Start(ctx context.Context) error {
+	if err := s.Client.Start(ctx); err != nil {
+		return err
+	}
+
+	go s.loop()
+
+	// Replay whatever a previous process left behind
+	s.trigger()
+
+	return nil
+}
+
+// Stop cancels a replay in progress and waits for it to end, up to the
+// deadline of ctx, before stopping the wrapped client.
+func (s *spoolClient) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.cancelDrain()
+	})
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return s.Client.Stop(ctx)
+}
+
+// UploadTraces exports the batch, spooling it when the collector cannot be
+// reached. Batches rejected by the collector are not spooled, as replaying
+// them would fail again.
+func (s *spoolClient) UploadTraces(ctx context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	if err := s.Client.UploadTraces(ctx, protoSpans); err != nil {
+		if !isRetryable(err) {
+			return err
+		}
+
+		if spoolErr := s.write(protoSpans); spoolErr != nil {
+			return errors.Join(err, spoolErr)
+		}
+
+		return nil
+	}
+
+	// The collector is reachable, good time to drain the backlog
+	s.trigger()
+
+	return nil
+}
+
+func (s *spoolClient) trigger() {
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (s *spoolClient) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.drainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		case <-s.kick:
+		}
+
+		s.drain()
+	}
+}
+
+// drain replays the spooled batches oldest first and stops at the first
+// retryable failure, leaving the rest for the next attempt.
+func (s *spoolClient) drain() {
+	files, err := s.files()
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		b, err := os.ReadFile(file.path)
+		if err != nil {
+			// Dropped by size enforcement in the meantime
+			continue
+		}
+
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(b, req); err != nil {
+			// Corrupted batch can never be delivered
+			os.Remove(file.path)
+			continue
+		}
+
+		if err := s.Client.UploadTraces(s.drainCtx, req.GetResourceSpans()); err != nil {
+			if isRetryable(err) {
+				return
+			}
+
+			// Rejected batch would block the spool forever
+			os.Remove(file.path)
+			continue
+		}
+
+		os.Remove(file.path)
+	}
+}
+
+// isRetryable reports whether err means the collector could not be reached
+// or asked to retry later: connection errors, timeouts, and the 429, 502, 503
+// and 504 responses of otlptracehttp.
+func isRetryable(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	// otlptracehttp does not export its retryable error type
+	return strings.Contains(err.Error(), "retry-able request failure")
+}
+
+// write stores the batch in the spool, dropping the oldest batches when the
+// spool would grow beyond its limit.
+func (s *spoolClient) write(protoSpans []*tracepb.ResourceSpans) error {
+	b, err := proto.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: protoSpans})
+	if err != nil {
+		return fmt.Errorf("marshal spool batch: %w", err)
+	}
+
+	if int64(len(b)) > s.maxBytes {
+		return fmt.Errorf("spool batch of %d bytes exceeds spool limit", len(b))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+
+	for len(files) > 0 && total+int64(len(b)) > s.maxBytes {
+		os.Remove(files[0].path)
+		total -= files[0].size
+		files = files[1:]
+	}
+
+	// Zero padded timestamp keeps lexical order equal to write order
+	name := fmt.Sprintf("%020d-%010d", time.Now().UnixNano(), s.seq.Add(1))
+	tmp := filepath.Join(s.dir, name+".tmp")
+
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("write spool batch: %w", err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(s.dir, name+spoolFileExt)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("commit spool batch: %w", err)
+	}
+
+	return nil
+}
+
+type spoolFile struct {
+	path string
+	size int64
+}
+
+// files lists the committed batches, oldest first.
+func (s *spoolClient) files() ([]spoolFile, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read spool dir: %w", err)
+	}
+
+	files := []spoolFile{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolFileExt) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, spoolFile{
+			path: filepath.Join(s.dir, entry.Name()),
+			size: info.Size(),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+
+	return files, nil
+}
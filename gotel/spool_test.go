@@ -0,0 +1,284 @@
+package gotel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// flakyCollector is an OTLP collector that can be taken down, or made to
+// fail every other export.
+type flakyCollector struct {
+	*httptest.Server
+
+	down     atomic.Bool
+	flaky    atomic.Bool
+	requests atomic.Int64
+
+	mu       sync.Mutex
+	received []string
+}
+
+func newFlakyCollector(t *testing.T) *flakyCollector {
+	c := &flakyCollector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := c.requests.Add(1)
+		if c.down.Load() || (c.flaky.Load() && n%2 == 0) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(b, req); err != nil || strings.Contains(string(b), "poison") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		c.mu.Lock()
+		for _, rs := range req.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				for _, span := range ss.GetSpans() {
+					c.received = append(c.received, span.GetName())
+				}
+			}
+		}
+		c.mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+func (c *flakyCollector) spans() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Clone(c.received)
+}
+
+func batch(name string) []*tracepb.ResourceSpans {
+	return []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{
+			Spans: []*tracepb.Span{{Name: name}},
+		}},
+	}}
+}
+
+func spooled(t *testing.T, dir string) int {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read spool dir: %v", err)
+	}
+
+	return len(entries)
+}
+
+// newTestSpool returns a started spool exporting to collector without retries.
+func newTestSpool(t *testing.T, collector *flakyCollector, dir string) *spoolClient {
+	t.Helper()
+
+	client := otlptracehttp.NewClient(
+		otlptracehttp.WithEndpoint(strings.TrimPrefix(collector.URL, "http://")),
+		otlptracehttp.WithInsecure(),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{Enabled: false}),
+	)
+
+	spool, err := newSpoolClient(client, SpoolOption{Dir: dir, DrainInterval: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("newSpoolClient: %v", err)
+	}
+
+	if err := spool.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { spool.Stop(context.Background()) })
+
+	return spool
+}
+
+// waitDrained waits for the spool in dir to be empty.
+func waitDrained(t *testing.T, dir string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for spooled(t, dir) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := spooled(t, dir); got != 0 {
+		t.Fatalf("spooled batches = %d after the collector came back, want 0", got)
+	}
+}
+
+func TestSpoolReplaysAfterOutage(t *testing.T) {
+	collector := newFlakyCollector(t)
+	dir := t.TempDir()
+	spool := newTestSpool(t, collector, dir)
+	ctx := context.Background()
+
+	// Collector is down: batches end up in the spool
+	collector.down.Store(true)
+	for i := range 4 {
+		if err := spool.UploadTraces(ctx, batch(fmt.Sprintf("span-%d", i))); err != nil {
+			t.Fatalf("UploadTraces while down: %v", err)
+		}
+	}
+
+	if got := spooled(t, dir); got != 4 {
+		t.Fatalf("spooled batches = %d, want 4", got)
+	}
+	if got := collector.spans(); len(got) != 0 {
+		t.Fatalf("collector received %v while down", got)
+	}
+
+	// Collector is back but fails every other export: the spool drains over
+	// several attempts, oldest first and without losing a batch
+	collector.flaky.Store(true)
+	collector.down.Store(false)
+
+	waitDrained(t, dir)
+
+	want := []string{"span-0", "span-1", "span-2", "span-3"}
+	if got := collector.spans(); !slices.Equal(got, want) {
+		t.Errorf("collector received %v, want %v", got, want)
+	}
+}
+
+func TestSpoolDropsRejectedBatches(t *testing.T) {
+	collector := newFlakyCollector(t)
+	dir := t.TempDir()
+	spool := newTestSpool(t, collector, dir)
+	ctx := context.Background()
+
+	// Rejected while the collector is up: reported, not spooled
+	if err := spool.UploadTraces(ctx, batch("poison-0")); err == nil {
+		t.Errorf("UploadTraces of a rejected batch succeeded")
+	}
+	if got := spooled(t, dir); got != 0 {
+		t.Fatalf("spooled batches = %d, want the rejected batch dropped", got)
+	}
+
+	// Spooled while down, rejected on replay: dropped without blocking the rest
+	collector.down.Store(true)
+	for _, name := range []string{"span-0", "poison-1", "span-2"} {
+		spool.UploadTraces(ctx, batch(name))
+	}
+	collector.down.Store(false)
+
+	waitDrained(t, dir)
+
+	want := []string{"span-0", "span-2"}
+	if got := collector.spans(); !slices.Equal(got, want) {
+		t.Errorf("collector received %v, want %v", got, want)
+	}
+}
+
+// blockingClient is an OTLP client whose exports hang until their context
+// is done, or for wait when ignoreCtx is set.
+type blockingClient struct {
+	uploading chan struct{}
+	ignoreCtx bool
+	wait      time.Duration
+}
+
+func (c *blockingClient) Start(context.Context) error { return nil }
+func (c *blockingClient) Stop(context.Context) error  { return nil }
+
+func (c *blockingClient) UploadTraces(ctx context.Context, _ []*tracepb.ResourceSpans) error {
+	select {
+	case c.uploading <- struct{}{}:
+	default:
+	}
+
+	if c.ignoreCtx {
+		time.Sleep(c.wait)
+		return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func newStuckSpool(t *testing.T, client *blockingClient) *spoolClient {
+	t.Helper()
+
+	dir := t.TempDir()
+	spool, err := newSpoolClient(client, SpoolOption{Dir: dir, DrainInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("newSpoolClient: %v", err)
+	}
+
+	// Left behind by a previous process, replayed on Start
+	if err := spool.write(batch("leftover")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := spool.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	select {
+	case <-client.uploading:
+	case <-time.After(time.Second):
+		t.Fatal("spool was not replayed on Start")
+	}
+
+	return spool
+}
+
+func TestSpoolStopCancelsReplay(t *testing.T) {
+	spool := newStuckSpool(t, &blockingClient{uploading: make(chan struct{}, 1)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := spool.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Stop took %v while a replay was in progress", elapsed)
+	}
+
+	// Stopping twice is harmless
+	if err := spool.Stop(context.Background()); err != nil {
+		t.Errorf("second Stop: %v", err)
+	}
+}
+
+func TestSpoolStopHonorsDeadline(t *testing.T) {
+	client := &blockingClient{uploading: make(chan struct{}, 1), ignoreCtx: true, wait: 2 * time.Second}
+	spool := newStuckSpool(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := spool.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Stop took %v, past the deadline of its context", elapsed)
+	}
+}
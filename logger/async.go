@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"bufio"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultAsyncBufferSize    = 1024
+	defaultAsyncFlushInterval = time.Second
+
+	// asyncWriteBufferSize is the size of the batch written to the output at once.
+	asyncWriteBufferSize = 256 * 1024
+)
+
+// asyncWriter queues encoded records and writes them to the output in the
+// background, batching writes until the flush interval elapses.
+type asyncWriter struct {
+	out           zapcore.WriteSyncer
+	policy        OverflowPolicy
+	flushInterval time.Duration
+
+	records chan []byte
+	flush   chan chan struct{}
+	dropped atomic.Uint64
+
+	closeOnce sync.Once
+	closed    atomic.Bool
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func newAsyncWriter(out zapcore.WriteSyncer, opt AsyncOption) *asyncWriter {
+	bufferSize := opt.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+
+	flushInterval := opt.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultAsyncFlushInterval
+	}
+
+	w := &asyncWriter{
+		out:           out,
+		policy:        opt.Policy,
+		flushInterval: flushInterval,
+		records:       make(chan []byte, bufferSize),
+		flush:         make(chan chan struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go w.loop()
+
+	return w
+}
+
+// Write queues the record, applying the overflow policy when the buffer is full.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	// Writer is gone, fallback to a synchronous write
+	if w.closed.Load() {
+		return w.out.Write(p)
+	}
+
+	// zap reuses the buffer once Write returns
+	record := make([]byte, len(p))
+	copy(record, p)
+
+	switch w.policy {
+	case OverflowDropNewest:
+		select {
+		case w.records <- record:
+		default:
+			w.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.records <- record:
+				return len(p), nil
+			default:
+			}
+
+			select {
+			case <-w.records:
+				w.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case w.records <- record:
+		case <-w.stop:
+			return w.out.Write(p)
+		}
+	}
+
+	return len(p), nil
+}
+
+// Sync writes every queued record to the output and syncs it.
+func (w *asyncWriter) Sync() error {
+	if w.closed.Load() {
+		return w.out.Sync()
+	}
+
+	flushed := make(chan struct{})
+	select {
+	case w.flush <- flushed:
+		<-flushed
+	case <-w.done:
+	}
+
+	return w.out.Sync()
+}
+
+// Close writes every queued record to the output and stops the writer.
+func (w *asyncWriter) Close() error {
+	w.closeOnce.Do(func() {
+		w.closed.Store(true)
+		close(w.stop)
+		<-w.done
+	})
+
+	return w.out.Sync()
+}
+
+// Dropped returns the number of records dropped by the overflow policy.
+func (w *asyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+func (w *asyncWriter) loop() {
+	defer close(w.done)
+
+	buf := bufio.NewWriterSize(w.out, asyncWriteBufferSize)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	drain := func() {
+		for {
+			select {
+			case record := <-w.records:
+				buf.Write(record)
+			default:
+				buf.Flush()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case record := <-w.records:
+			buf.Write(record)
+		case <-ticker.C:
+			buf.Flush()
+		case flushed := <-w.flush:
+			drain()
+			close(flushed)
+		case <-w.stop:
+			drain()
+			return
+		}
+	}
+}
+
+// DroppedRecords returns the number of records l dropped because its async
+// buffer was full or a socket sink was down. It returns 0 when l does neither.
+func DroppedRecords(l Logger) uint64 {
+	if d, ok := l.(interface{ DroppedRecords() uint64 }); ok {
+		return d.DroppedRecords()
+	}
+
+	return 0
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedOutput blocks writes until it is opened, so the async writer can be
+// stalled with a full buffer.
+type gatedOutput struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	writing chan struct{}
+	gate    chan struct{}
+}
+
+func newGatedOutput() *gatedOutput {
+	return &gatedOutput{writing: make(chan struct{}, 1), gate: make(chan struct{})}
+}
+
+func (o *gatedOutput) Write(p []byte) (int, error) {
+	select {
+	case o.writing <- struct{}{}:
+	default:
+	}
+	<-o.gate
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.Write(p)
+}
+
+func (o *gatedOutput) Sync() error { return nil }
+
+func (o *gatedOutput) open() { close(o.gate) }
+
+func (o *gatedOutput) records() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return strings.Fields(o.buf.String())
+}
+
+// stall writes a first record and waits until the writer is stuck writing it,
+// leaving the buffer to fill up.
+func stall(t *testing.T, w *asyncWriter, out *gatedOutput) {
+	t.Helper()
+
+	w.Write([]byte("a\n"))
+	go w.Sync()
+
+	select {
+	case <-out.writing:
+	case <-time.After(time.Second):
+		t.Fatal("async writer did not write to the output")
+	}
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   []string
+	}{
+		{policy: OverflowDropNewest, want: []string{"a", "b", "c"}},
+		{policy: OverflowDropOldest, want: []string{"a", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			out := newGatedOutput()
+			w := newAsyncWriter(out, AsyncOption{BufferSize: 2, FlushInterval: time.Hour, Policy: tt.policy})
+
+			stall(t, w, out)
+			for _, record := range []string{"b", "c", "d"} {
+				w.Write([]byte(record + "\n"))
+			}
+
+			if got := w.Dropped(); got != 1 {
+				t.Errorf("Dropped = %d, want 1", got)
+			}
+
+			out.open()
+			w.Sync()
+
+			if got := out.records(); !slices.Equal(got, tt.want) {
+				t.Errorf("records = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsyncWriterBlockWaits(t *testing.T) {
+	out := newGatedOutput()
+	w := newAsyncWriter(out, AsyncOption{BufferSize: 1, FlushInterval: time.Hour, Policy: OverflowBlock})
+
+	stall(t, w, out)
+	w.Write([]byte("b\n"))
+
+	written := make(chan struct{})
+	go func() {
+		w.Write([]byte("c\n"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("Write returned while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	out.open()
+	<-written
+	w.Close()
+
+	if got, want := out.records(), []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+	if got := w.Dropped(); got != 0 {
+		t.Errorf("Dropped = %d, want 0", got)
+	}
+}
+
+func TestAsyncWriterClose(t *testing.T) {
+	out := newGatedOutput()
+	out.open()
+
+	w := newAsyncWriter(out, AsyncOption{FlushInterval: time.Hour})
+	w.Write([]byte("queued\n"))
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// Records written once closed go straight to the output
+	w.Write([]byte("late\n"))
+
+	if got, want := out.records(), []string{"queued", "late"}; !slices.Equal(got, want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// EncodingEnv is the environment variable selecting the encoding when
+// Option.Encoding is not set, such as LOGGER_ENCODING=console.
+const EncodingEnv = "LOGGER_ENCODING"
+
+// Encoding is the format records are written in.
+type Encoding string
+
+const (
+	// EncodingJSON writes a JSON object per record.
+	EncodingJSON Encoding = "json"
+	// EncodingConsole writes human readable, colored records for local
+	// development. Colors are disabled when NO_COLOR is set to a non-empty value.
+	EncodingConsole Encoding = "console"
+)
+
+// resolveEncoding returns encoding, falling back to EncodingEnv and then to
+// EncodingJSON.
+func resolveEncoding(encoding Encoding) Encoding {
+	if encoding == "" {
+		encoding = Encoding(os.Getenv(EncodingEnv))
+	}
+
+	if encoding == EncodingConsole {
+		return EncodingConsole
+	}
+
+	return EncodingJSON
+}
+
+// newEncoder returns the zap encoder of the given encoding. Console records
+// are colored when color is set, unless NO_COLOR is set.
+func newEncoder(encoding Encoding, cfg zapcore.EncoderConfig, color bool) zapcore.Encoder {
+	if resolveEncoding(encoding) == EncodingConsole {
+		return newConsoleEncoder(color && os.Getenv("NO_COLOR") == "")
+	}
+
+	return zapcore.NewJSONEncoder(cfg)
+}
+
+const (
+	colorReset  = "\x1b[0m"
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorCyan   = "\x1b[36m"
+	colorPurple = "\x1b[35m"
+)
+
+var consolePool = buffer.NewPool()
+
+// consoleEncoder writes a record as a header line followed by its payloads:
+//
+//	2025-02-26 12:13:21.103 INFO  TDR  trace=b03b1bba.. span=e4fa02ad..
+//	  Logger Service@v1.0.0:9000 POST /test 200 29.28ms
+//	  app_request: {
+//	    "amount": 100000
+//	  }
+type consoleEncoder struct {
+	// Fields added with With, written after the fields of the record
+	*zapcore.MapObjectEncoder
+	color bool
+}
+
+func newConsoleEncoder(color bool) *consoleEncoder {
+	return &consoleEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), color: color}
+}
+
+func (e *consoleEncoder) Clone() zapcore.Encoder {
+	clone := newConsoleEncoder(e.color)
+	maps.Copy(clone.Fields, e.Fields)
+
+	return clone
+}
+
+// consoleField is a field of the record once encoded.
+type consoleField struct {
+	key string
+	val interface{}
+}
+
+func (e *consoleEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf := consolePool.Get()
+
+	values := make(map[string]interface{}, len(fields))
+	var ordered []consoleField
+	for _, field := range fields {
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+
+		for key, val := range enc.Fields {
+			values[key] = val
+			ordered = append(ordered, consoleField{key: key, val: val})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(e.Fields)) {
+		values[key] = e.Fields[key]
+		ordered = append(ordered, consoleField{key: key, val: e.Fields[key]})
+	}
+
+	// Header: time, level, message and trace context
+	buf.AppendString(e.paint(colorDim, ent.Time.Format("2006-01-02 15:04:05.000")))
+	buf.AppendByte(' ')
+	buf.AppendString(e.paint(levelColor(ent.Level), fmt.Sprintf("%-5s", ent.Level.CapitalString())))
+	buf.AppendByte(' ')
+	buf.AppendString(ent.Message)
+	if id := consoleString(values["trace.id"]); strings.Trim(id, "0") != "" {
+		buf.AppendString(e.paint(colorCyan, "  trace="+id))
+	}
+	if id := consoleString(values["span.id"]); strings.Trim(id, "0") != "" {
+		buf.AppendString(e.paint(colorCyan, " span="+id))
+	}
+	if ent.Caller.Defined {
+		buf.AppendString(e.paint(colorDim, "  "+ent.Caller.TrimmedPath()))
+	}
+
+	// Request line: the app_* fields that identify the request
+	if line := consoleRequestLine(values); line != "" {
+		buf.AppendString("\n  ")
+		buf.AppendString(line)
+	}
+
+	// Scalar fields on one line, payloads pretty printed below
+	var scalars []string
+	var payloads []consoleField
+	for _, field := range ordered {
+		if consoleHeaderKeys[field.key] {
+			continue
+		}
+
+		if str, ok := consoleScalar(field.val); ok {
+			scalars = append(scalars, e.paint(colorDim, field.key+"=")+str)
+		} else {
+			payloads = append(payloads, field)
+		}
+	}
+	if len(scalars) > 0 {
+		buf.AppendString("\n  ")
+		buf.AppendString(strings.Join(scalars, " "))
+	}
+	for _, field := range payloads {
+		data, err := json.MarshalIndent(field.val, "  ", "  ")
+		if err != nil {
+			data = []byte(fmt.Sprintf("%+v", field.val))
+		}
+
+		buf.AppendString("\n  ")
+		buf.AppendString(e.paint(colorDim, field.key+": "))
+		buf.AppendBytes(data)
+	}
+
+	if ent.Stack != "" {
+		buf.AppendString("\n")
+		buf.AppendString(e.paint(colorDim, ent.Stack))
+	}
+
+	buf.AppendByte('\n')
+	return buf, nil
+}
+
+func (e *consoleEncoder) paint(color, text string) string {
+	if !e.color {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+func levelColor(level zapcore.Level) string {
+	switch {
+	case level >= zapcore.ErrorLevel:
+		return colorRed
+	case level == zapcore.WarnLevel:
+		return colorYellow
+	case level == zapcore.InfoLevel:
+		return colorBlue
+	default:
+		return colorPurple
+	}
+}
+
+// consoleHeaderKeys are the fields written in the header and request line.
+var consoleHeaderKeys = map[string]bool{
+	"trace.id":          true,
+	"span.id":           true,
+	"app_name":          true,
+	"app_version":       true,
+	"app_port":          true,
+	"app_tag":           true,
+	"app_method":        true,
+	"app_uri":           true,
+	"app_response_code": true,
+	"app_exec_time":     true,
+}
+
+// consoleRequestLine writes the app_* fields compactly, such as
+// "Logger Service@v1.0.0:9000 [tag] POST /test 200 29.28ms". Empty values are
+// left out.
+func consoleRequestLine(values map[string]interface{}) string {
+	var parts []string
+
+	app := consoleString(values["app_name"])
+	if version := consoleString(values["app_version"]); version != "" {
+		app += "@" + version
+	}
+	if port := consoleString(values["app_port"]); port != "" && port != "0" {
+		app += ":" + port
+	}
+	if app != "" {
+		parts = append(parts, app)
+	}
+
+	if tag := consoleString(values["app_tag"]); tag != "" {
+		parts = append(parts, "["+tag+"]")
+	}
+
+	for _, key := range []string{"app_method", "app_uri", "app_response_code", "app_exec_time"} {
+		if val := consoleString(values[key]); val != "" {
+			parts = append(parts, val)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// consoleString formats a scalar field value, returning "" for missing ones.
+func consoleString(val interface{}) string {
+	if val == nil {
+		return ""
+	}
+
+	str, _ := consoleScalar(val)
+	return strings.Trim(str, `"`)
+}
+
+// consoleScalar formats val when it fits on a line, strings are quoted when
+// they contain spaces.
+func consoleScalar(val interface{}) (string, bool) {
+	switch v := val.(type) {
+	case nil:
+		return "null", true
+	case string:
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			return strconv.Quote(v), true
+		}
+		return v, true
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true
+	case time.Duration:
+		return v.String(), true
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64, complex64, complex128:
+		return fmt.Sprint(v), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func encodeConsole(t *testing.T, enc zapcore.Encoder, ent zapcore.Entry, fields ...zapcore.Field) string {
+	t.Helper()
+
+	buf, err := enc.EncodeEntry(ent, fields)
+	if err != nil {
+		t.Fatalf("EncodeEntry: %v", err)
+	}
+	defer buf.Free()
+
+	return buf.String()
+}
+
+func TestConsoleEncoderTDR(t *testing.T) {
+	ent := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Date(2025, 2, 26, 12, 13, 21, 103000000, time.UTC),
+		Message: tdrMessage,
+	}
+
+	got := encodeConsole(t, newConsoleEncoder(false), ent,
+		zap.String("trace.id", "b03b1bba5c1e4f7b8a1f0e2d3c4b5a69"),
+		zap.String("span.id", "e4fa02ad1c2b3d4e"),
+		zap.String("app_name", "Logger Service"),
+		zap.String("app_version", "v1.0.0"),
+		zap.Int("app_port", 9000),
+		zap.String("app_tag", ""),
+		zap.String("app_method", "POST"),
+		zap.String("app_uri", "/test"),
+		zap.Int("app_response_code", 200),
+		zap.String("app_exec_time", "29.28ms"),
+		zap.String("user_id", "u-1"),
+		zap.String("note", "paid in full"),
+		zap.Any("app_request", map[string]interface{}{"amount": 100000, "items": []string{"a"}}),
+		zap.Any("app_response", nil),
+	)
+
+	want := `2025-02-26 12:13:21.103 INFO  TDR  trace=b03b1bba5c1e4f7b8a1f0e2d3c4b5a69 span=e4fa02ad1c2b3d4e
+  Logger Service@v1.0.0:9000 POST /test 200 29.28ms
+  user_id=u-1 note="paid in full" app_response=null
+  app_request: {
+    "amount": 100000,
+    "items": [
+      "a"
+    ]
+  }
+`
+	if got != want {
+		t.Errorf("console output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConsoleEncoderHeader(t *testing.T) {
+	ent := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Date(2025, 2, 26, 12, 13, 21, 0, time.UTC),
+		Message: "retrying charge",
+		Caller:  zapcore.NewEntryCaller(0, "/src/gobang/logger/payment.go", 42, true),
+		Stack:   "main.charge\n\t/src/main.go:10",
+	}
+
+	// Fields added with With come after those of the record, zero trace ids are left out
+	enc := newConsoleEncoder(false)
+	enc.AddString("app_component", "payment")
+
+	got := encodeConsole(t, enc, ent,
+		zap.String("trace.id", "00000000000000000000000000000000"),
+		zap.String("span.id", "0000000000000000"),
+		zap.Int("attempt", 2),
+		zap.Bool("retryable", true),
+	)
+
+	want := `2025-02-26 12:13:21.000 WARN  retrying charge  logger/payment.go:42
+  attempt=2 retryable=true app_component=payment
+main.charge
+	/src/main.go:10
+`
+	if got != want {
+		t.Errorf("console output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConsoleEncoderColor(t *testing.T) {
+	ent := zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    time.Date(2025, 2, 26, 12, 13, 21, 0, time.UTC),
+		Message: "charge failed",
+	}
+
+	got := encodeConsole(t, newConsoleEncoder(true), ent)
+	want := colorDim + "2025-02-26 12:13:21.000" + colorReset + " " + colorRed + "ERROR" + colorReset + " charge failed\n"
+	if got != want {
+		t.Errorf("console output = %q, want %q", got, want)
+	}
+}
+
+func TestNewEncoder(t *testing.T) {
+	cfg := getEncoderConfig()
+
+	tests := []struct {
+		name      string
+		encoding  Encoding
+		env       string
+		noColor   string
+		color     bool
+		wantColor bool
+		console   bool
+	}{
+		{name: "default", console: false},
+		{name: "option", encoding: EncodingConsole, color: true, wantColor: true, console: true},
+		{name: "env", env: "console", color: true, wantColor: true, console: true},
+		{name: "option over env", encoding: EncodingJSON, env: "console", console: false},
+		{name: "unknown env", env: "yaml", console: false},
+		{name: "no color", encoding: EncodingConsole, noColor: "1", color: true, wantColor: false, console: true},
+		{name: "empty no color", encoding: EncodingConsole, color: true, wantColor: true, console: true},
+		{name: "not a terminal", encoding: EncodingConsole, color: false, wantColor: false, console: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EncodingEnv, tt.env)
+			t.Setenv("NO_COLOR", tt.noColor)
+
+			enc := newEncoder(tt.encoding, cfg, tt.color)
+
+			console, ok := enc.(*consoleEncoder)
+			if ok != tt.console {
+				t.Fatalf("encoder = %T, want console %v", enc, tt.console)
+			}
+			if ok && console.color != tt.wantColor {
+				t.Errorf("color = %v, want %v", console.color, tt.wantColor)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// contextHolder is the request-scoped, concurrency-safe store of the logger
+// Context injected by InjectCtx.
+type contextHolder struct {
+	mu  sync.RWMutex
+	ctx Context
+	err error
+
+	// respSet reports whether the response was set with SetResponse
+	respSet bool
+}
+
+func newContextHolder(ctx Context) *contextHolder {
+	ctx.AdditionalData = maps.Clone(ctx.AdditionalData)
+	return &contextHolder{ctx: ctx}
+}
+
+// outcome returns the error set by SetError and whether the response was set
+// with SetResponse.
+func (h *contextHolder) outcome() (error, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.err, h.respSet
+}
+
+func holderFromCtx(ctx context.Context) *contextHolder {
+	if ctx == nil {
+		return nil
+	}
+
+	holder, _ := ctx.Value(ctxKey).(*contextHolder)
+	return holder
+}
+
+// extractCtxWithError extracts a snapshot of the logger context and the error
+// set by SetError from the given context.
+func extractCtxWithError(ctx context.Context) (Context, error) {
+	holder := holderFromCtx(ctx)
+	if holder == nil {
+		return Context{}, nil
+	}
+
+	return holder.snapshot()
+}
+
+// snapshot returns a copy of the held Context and the error set by SetError.
+// Context.Error falls back to the message of that error.
+func (h *contextHolder) snapshot() (Context, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	val := h.ctx
+	val.AdditionalData = maps.Clone(h.ctx.AdditionalData)
+	if val.Error == "" && h.err != nil {
+		val.Error = h.err.Error()
+	}
+
+	return val, h.err
+}
+
+// complete fills in what the middleware captured once the request is done.
+// The response and error are kept when they were set explicitly.
+func (h *contextHolder) complete(reqBody, respBody interface{}, respCode int, respTime string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.ctx.ReqBody = reqBody
+	h.ctx.RespTime = respTime
+	if !h.respSet {
+		h.ctx.RespBody = respBody
+		h.ctx.RespCode = respCode
+	}
+	if h.err == nil && err != nil {
+		h.err = err
+	}
+}
+
+// SetResponse records the response body and code of the request held by ctx.
+// Values set here take precedence over what the HTTP middleware and gRPC
+// interceptors capture. It does nothing when ctx holds no logger Context.
+func SetResponse(ctx context.Context, code int, body interface{}) {
+	holder := holderFromCtx(ctx)
+	if holder == nil {
+		return
+	}
+
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+
+	holder.ctx.RespCode = code
+	holder.ctx.RespBody = body
+	holder.respSet = true
+}
+
+// AddData adds a key to the app_data of the request held by ctx. It does
+// nothing when ctx holds no logger Context.
+func AddData(ctx context.Context, key string, val interface{}) {
+	holder := holderFromCtx(ctx)
+	if holder == nil {
+		return
+	}
+
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+
+	if holder.ctx.AdditionalData == nil {
+		holder.ctx.AdditionalData = make(map[string]interface{})
+	}
+	holder.ctx.AdditionalData[key] = val
+}
+
+// SetError records the error of the request held by ctx, it is logged as
+// app_error by TDR. It does nothing when ctx holds no logger Context.
+func SetError(ctx context.Context, err error) {
+	holder := holderFromCtx(ctx)
+	if holder == nil {
+		return
+	}
+
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+
+	holder.err = err
+	holder.ctx.Error = ""
+}
+
+// ctxKeyChildLogger is the context key for the logger attached by WithLogger.
+type ctxKeyChildLogger struct{}
+
+// WithLogger attaches l to the returned context, so downstream code logging
+// through FromContext picks it up.
+func WithLogger(parent context.Context, l Logger) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	return context.WithValue(parent, ctxKeyChildLogger{}, l)
+}
+
+// FromContext returns the logger attached to ctx by WithLogger, or the global
+// Log when there is none.
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKeyChildLogger{}).(Logger); ok {
+			return l
+		}
+	}
+
+	return Log
+}
@@ -0,0 +1,60 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+)
+
+func TestInjectCtxSetters(t *testing.T) {
+	ctx := logger.InjectCtx(context.Background(), logger.Context{ServiceName: "orders"})
+
+	logger.AddData(ctx, "user_id", "u-1")
+	logger.SetResponse(ctx, 201, map[string]string{"status": "created"})
+	logger.SetError(ctx, errors.New("slow downstream"))
+
+	got := logger.ExtractCtx(ctx)
+	if got.ServiceName != "orders" || got.RespCode != 201 || got.Error != "slow downstream" {
+		t.Errorf("ExtractCtx = %+v", got)
+	}
+	if got.AdditionalData["user_id"] != "u-1" {
+		t.Errorf("app_data = %v, want user_id", got.AdditionalData)
+	}
+
+	// Snapshots are copies
+	got.AdditionalData["user_id"] = "changed"
+	if logger.ExtractCtx(ctx).AdditionalData["user_id"] != "u-1" {
+		t.Errorf("modifying a snapshot changed the held context")
+	}
+}
+
+func TestInjectCtxCopiesParent(t *testing.T) {
+	in := logger.InjectCtx(context.Background(), logger.Context{ServiceName: "orders", ReqMethod: "POST", ReqURI: "/orders"})
+	logger.SetError(in, errors.New("card declined"))
+
+	// An outbound call logged with its own request, the inbound one is unchanged
+	out := logger.InjectCtx(in, logger.Context{ReqMethod: "GET", ReqURI: "http://payments/charge"})
+	logger.AddData(out, "attempt", 1)
+
+	got := logger.ExtractCtx(in)
+	if got.ReqMethod != "POST" || got.ReqURI != "/orders" {
+		t.Errorf("inbound request = %s %s, want POST /orders", got.ReqMethod, got.ReqURI)
+	}
+	if got.AdditionalData != nil {
+		t.Errorf("inbound app_data = %v, want the data of the outbound call left out", got.AdditionalData)
+	}
+
+	// The copy starts from the error recorded on the parent
+	if got := logger.ExtractCtx(out); got.ReqURI != "http://payments/charge" || got.Error != "card declined" {
+		t.Errorf("outbound context = %+v, want its own request and the parent error", got)
+	}
+}
+
+func TestInjectCtxNilParent(t *testing.T) {
+	ctx := logger.InjectCtx(nil, logger.Context{ServiceName: "orders"})
+	if got := logger.ExtractCtx(ctx).ServiceName; got != "orders" {
+		t.Errorf("ServiceName = %q, want orders", got)
+	}
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+)
+
+// maxErrorChain limits the number of errors logged from a single chain.
+const maxErrorChain = 32
+
+// errorEntry is one error of a chain logged in app_error_chain.
+type errorEntry struct {
+	Message    string `json:"message"`
+	Type       string `json:"type"`
+	StackTrace string `json:"stacktrace,omitempty"`
+}
+
+// errorChain flattens err and everything it wraps, depth first. Both
+// Unwrap() error and the Unwrap() []error of errors.Join are followed.
+func errorChain(err error) []errorEntry {
+	var chain []errorEntry
+
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil || len(chain) >= maxErrorChain {
+			return
+		}
+
+		chain = append(chain, errorEntry{
+			Message:    err.Error(),
+			Type:       fmt.Sprintf("%T", err),
+			StackTrace: stackTraceOf(err),
+		})
+
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(wrapped.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, e := range wrapped.Unwrap() {
+				walk(e)
+			}
+		}
+	}
+	walk(err)
+
+	return chain
+}
+
+// stackTraceOf returns the stack trace carried by err, if any. Errors are
+// expected to have a StackTrace method, as github.com/pkg/errors does. It is
+// looked up by reflection so the error packages need not be imported.
+func stackTraceOf(err error) string {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+
+	switch st := method.Call(nil)[0].Interface().(type) {
+	case string:
+		return st
+	case []uintptr:
+		return formatFrames(st)
+	default:
+		// Stack traces of github.com/pkg/errors print their frames with %+v
+		return strings.TrimSpace(fmt.Sprintf("%+v", st))
+	}
+}
+
+// formatFrames formats program counters the way runtime/debug.Stack does.
+func formatFrames(pcs []uintptr) string {
+	var sb strings.Builder
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// tdrLevel picks the level of a TDR. Client errors are logged at warn level,
+// server errors at error level, other requests at error level if they carry
+// an error and at info level otherwise.
+func tdrLevel(ctxVal Context, err error) zapcore.Level {
+	if ctxVal.ReqMethod == grpcMethod {
+		switch codes.Code(ctxVal.RespCode) {
+		case codes.OK:
+			// Decided by the error below
+		case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+			codes.PermissionDenied, codes.FailedPrecondition, codes.OutOfRange, codes.Unauthenticated:
+			return zap.WarnLevel
+		default:
+			return zap.ErrorLevel
+		}
+	} else {
+		switch {
+		case ctxVal.RespCode >= http.StatusInternalServerError:
+			return zap.ErrorLevel
+		case ctxVal.RespCode >= http.StatusBadRequest:
+			return zap.WarnLevel
+		}
+	}
+
+	if err != nil || ctxVal.Error != "" {
+		return zap.ErrorLevel
+	}
+
+	return zap.InfoLevel
+}
@@ -0,0 +1,102 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// tracedError carries its stack trace like github.com/pkg/errors does.
+type tracedError struct {
+	msg string
+}
+
+func (e *tracedError) Error() string { return e.msg }
+
+func (e *tracedError) StackTrace() string { return "main.handler\n\tmain.go:42" }
+
+func logTDR(t *testing.T, ctxVal logger.Context, err error) logger.Entry {
+	t.Helper()
+
+	tl := logger.NewTestLogger()
+
+	ctx := logger.InjectCtx(context.Background(), ctxVal)
+	if err != nil {
+		logger.SetError(ctx, err)
+	}
+	tl.TDR(ctx)
+
+	tdrs := tl.TDRs()
+	if len(tdrs) != 1 {
+		t.Fatalf("TDR records = %d, want 1", len(tdrs))
+	}
+
+	return tdrs[0]
+}
+
+func TestTDRLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctxVal logger.Context
+		err    error
+		want   zapcore.Level
+	}{
+		{name: "ok", ctxVal: logger.Context{ReqMethod: http.MethodGet, RespCode: http.StatusOK}, want: zapcore.InfoLevel},
+		{name: "ok with error", ctxVal: logger.Context{ReqMethod: http.MethodGet, RespCode: http.StatusOK}, err: errors.New("cache down"), want: zapcore.ErrorLevel},
+		{name: "client error", ctxVal: logger.Context{ReqMethod: http.MethodGet, RespCode: http.StatusNotFound}, want: zapcore.WarnLevel},
+		{name: "server error", ctxVal: logger.Context{ReqMethod: http.MethodGet, RespCode: http.StatusBadGateway}, want: zapcore.ErrorLevel},
+		{name: "grpc ok", ctxVal: logger.Context{ReqMethod: "GRPC", RespCode: 0}, want: zapcore.InfoLevel},
+		{name: "grpc invalid argument", ctxVal: logger.Context{ReqMethod: "GRPC", RespCode: 3}, want: zapcore.WarnLevel},
+		{name: "grpc unavailable", ctxVal: logger.Context{ReqMethod: "GRPC", RespCode: 14}, want: zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logTDR(t, tt.ctxVal, tt.err).Level; got != tt.want {
+				t.Errorf("level = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTDRErrorChain(t *testing.T) {
+	root := &tracedError{msg: "connection refused"}
+	err := fmt.Errorf("load order: %w", errors.Join(root, errors.New("retry exhausted")))
+
+	tdr := logTDR(t, logger.Context{ReqMethod: http.MethodGet, RespCode: http.StatusInternalServerError}, err)
+
+	if got, _ := tdr.Lookup("app_error"); got != err.Error() {
+		t.Errorf("app_error = %v, want %v", got, err)
+	}
+
+	val, _ := tdr.Lookup("app_error_chain")
+	chain, _ := val.([]interface{})
+
+	want := []string{err.Error(), "connection refused\nretry exhausted", "connection refused", "retry exhausted"}
+	if len(chain) != len(want) {
+		t.Fatalf("app_error_chain = %v, want %d errors", val, len(want))
+	}
+	for i, entry := range chain {
+		if got := entry.(map[string]interface{})["message"]; got != want[i] {
+			t.Errorf("app_error_chain[%d].message = %v, want %q", i, got, want[i])
+		}
+	}
+
+	traced := chain[2].(map[string]interface{})
+	if traced["type"] != "*logger_test.tracedError" || traced["stacktrace"] != root.StackTrace() {
+		t.Errorf("app_error_chain[2] = %v, want the stack trace of the root error", traced)
+	}
+}
+
+func TestTDRSingleError(t *testing.T) {
+	tdr := logTDR(t, logger.Context{ReqMethod: http.MethodGet, RespCode: http.StatusOK}, errors.New("cache down"))
+
+	if _, ok := tdr.Lookup("app_error_chain"); ok {
+		t.Errorf("app_error_chain is logged for a single error without stack trace")
+	}
+}
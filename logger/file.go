@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"math"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotatingFile is a log file rotated by size and time, it is reopened on
+// SIGHUP so external tools like logrotate can move it away.
+type rotatingFile struct {
+	*lumberjack.Logger
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func newRotatingFile(opt FileOption) *rotatingFile {
+	maxAge := 0
+	if opt.MaxAge > 0 {
+		// lumberjack retention is expressed in whole days
+		maxAge = int(math.Ceil(opt.MaxAge.Hours() / 24))
+	}
+
+	f := &rotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   opt.Path,
+			MaxSize:    opt.MaxSizeMB,
+			MaxAge:     maxAge,
+			MaxBackups: opt.MaxBackups,
+			LocalTime:  true,
+			Compress:   opt.Compress,
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go f.loop(opt)
+
+	return f
+}
+
+// loop rotates the file on every RotateInterval and reopens it on SIGHUP.
+func (f *rotatingFile) loop(opt FileOption) {
+	defer close(f.done)
+
+	var tick <-chan time.Time
+	if opt.RotateInterval > 0 {
+		ticker := time.NewTicker(opt.RotateInterval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	var hup chan os.Signal
+	if opt.ReopenOnSIGHUP {
+		hup = make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+	}
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-tick:
+			f.Logger.Rotate()
+		case <-hup:
+			// Closed file is reopened, or created, on the next write
+			f.Logger.Close()
+		}
+	}
+}
+
+// Sync is a no-op, every write goes straight to the file.
+func (f *rotatingFile) Sync() error {
+	return nil
+}
+
+// Close stops the rotation and closes the file.
+func (f *rotatingFile) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.stop)
+		<-f.done
+	})
+
+	return f.Logger.Close()
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileCloseTwice(t *testing.T) {
+	f := newRotatingFile(FileOption{Path: filepath.Join(t.TempDir(), "app.log")})
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestNewZapLoggerWithClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	zapLogger, closeLogger := NewZapLoggerWithClose(Option{File: FileOption{Path: path}})
+	zapLogger.Info("written")
+
+	if err := closeLogger(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := closeLogger(); err != nil {
+		t.Errorf("second close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"message":"written"`) {
+		t.Errorf("log file = %s, want the written record", data)
+	}
+}
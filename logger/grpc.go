@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcMethod is the app_method of gRPC calls, app_uri holds the full method name.
+const grpcMethod = "GRPC"
+
+// NewUnaryServerInterceptor returns an interceptor that injects the logger
+// Context of every unary call and logs its TDR once the call completes.
+// app_response_code holds the gRPC status code. Credential metadata, such as
+// authorization and cookie, is logged masked.
+func NewUnaryServerInterceptor(opt GRPCInterceptorOption) grpc.UnaryServerInterceptor {
+	skipMethods := toSet(opt.SkipMethods)
+	sensitiveMetadata := headerSet(credentialHeaders, opt.SensitiveMetadata)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if skipMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+
+		ctxLogger := newGRPCContext(ctx, opt, info.FullMethod, sensitiveMetadata)
+		ctx = InjectCtx(ctx, ctxLogger)
+
+		resp, err := handler(ctx, req)
+
+		holderFromCtx(ctx).complete(req, resp, int(status.Code(err)), time.Since(start).String(), err)
+
+		if l := FromContext(ctx); l != nil {
+			l.TDR(ctx)
+		}
+
+		return resp, err
+	}
+}
+
+// NewStreamServerInterceptor returns an interceptor that logs every message
+// of a stream at debug level and a summary TDR once the stream completes.
+func NewStreamServerInterceptor(opt GRPCInterceptorOption) grpc.StreamServerInterceptor {
+	skipMethods := toSet(opt.SkipMethods)
+	sensitiveMetadata := headerSet(credentialHeaders, opt.SensitiveMetadata)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if skipMethods[info.FullMethod] {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+
+		ctxLogger := newGRPCContext(ss.Context(), opt, info.FullMethod, sensitiveMetadata)
+		stream := &loggedServerStream{
+			ServerStream: ss,
+			ctx:          InjectCtx(ss.Context(), ctxLogger),
+			method:       info.FullMethod,
+		}
+
+		err := handler(srv, stream)
+
+		AddData(stream.ctx, "stream_received", stream.received.Load())
+		AddData(stream.ctx, "stream_sent", stream.sent.Load())
+		holderFromCtx(stream.ctx).complete(nil, nil, int(status.Code(err)), time.Since(start).String(), err)
+
+		if l := FromContext(stream.ctx); l != nil {
+			l.TDR(stream.ctx)
+		}
+
+		return err
+	}
+}
+
+// newGRPCContext builds the logger Context of a call, keeping whatever an
+// outer interceptor already injected. Sensitive metadata is masked.
+func newGRPCContext(ctx context.Context, opt GRPCInterceptorOption, fullMethod string, sensitive map[string]bool) Context {
+	ctxLogger := ExtractCtx(ctx)
+	ctxLogger.ServiceName = valueOr(opt.ServiceName, ctxLogger.ServiceName)
+	ctxLogger.ServiceVersion = valueOr(opt.ServiceVersion, ctxLogger.ServiceVersion)
+	ctxLogger.Tag = valueOr(opt.Tag, ctxLogger.Tag)
+	if opt.ServicePort != 0 {
+		ctxLogger.ServicePort = opt.ServicePort
+	}
+	ctxLogger.ReqMethod = grpcMethod
+	ctxLogger.ReqURI = fullMethod
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctxLogger.ReqHeader = maskHeaders(md.Copy(), sensitive)
+	}
+
+	return ctxLogger
+}
+
+// loggedServerStream logs every message sent and received on the stream.
+type loggedServerStream struct {
+	grpc.ServerStream
+
+	ctx      context.Context
+	method   string
+	received atomic.Int64
+	sent     atomic.Int64
+}
+
+func (s *loggedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggedServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+
+		if l := FromContext(s.ctx); l != nil {
+			l.Debug(s.ctx, fmt.Sprintf("[gRPC Stream Recv] %s", s.method), m)
+		}
+	}
+
+	return err
+}
+
+func (s *loggedServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+
+		if l := FromContext(s.ctx); l != nil {
+			l.Debug(s.ctx, fmt.Sprintf("[gRPC Stream Send] %s", s.method), m)
+		}
+	}
+
+	return err
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, val := range values {
+		set[val] = true
+	}
+
+	return set
+}
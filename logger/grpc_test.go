@@ -0,0 +1,172 @@
+package logger_test
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"github.com/insaneadinesia/gobang/logger/logtest"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		code  codes.Code
+		level zapcore.Level
+	}{
+		{name: "ok", code: codes.OK, level: zapcore.InfoLevel},
+		{name: "client error", err: status.Error(codes.NotFound, "order not found"), code: codes.NotFound, level: zapcore.WarnLevel},
+		{name: "server error", err: status.Error(codes.Internal, "db down"), code: codes.Internal, level: zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := logger.NewTestLogger()
+			interceptor := logger.NewUnaryServerInterceptor(logger.GRPCInterceptorOption{ServiceName: "orders"})
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1"))
+			info := &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Get"}
+
+			interceptor(ctx, map[string]string{"id": "1"}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return map[string]string{"status": "paid"}, tt.err
+			})
+
+			tdrs := tl.TDRs()
+			if len(tdrs) != 1 {
+				t.Fatalf("TDR records = %d, want 1", len(tdrs))
+			}
+
+			tdr := tdrs[0]
+			if tdr.Level != tt.level {
+				t.Errorf("level = %v, want %v", tdr.Level, tt.level)
+			}
+			if got, _ := tdr.Lookup("app_response_code"); got != float64(tt.code) {
+				t.Errorf("app_response_code = %v, want %d", got, tt.code)
+			}
+			if got, _ := tdr.Lookup("app_method"); got != "GRPC" {
+				t.Errorf("app_method = %v, want GRPC", got)
+			}
+			if got, _ := tdr.Lookup("app_uri"); got != info.FullMethod {
+				t.Errorf("app_uri = %v, want %s", got, info.FullMethod)
+			}
+			if got, _ := tdr.Lookup("app_request.id"); got != "1" {
+				t.Errorf("app_request.id = %v, want 1", got)
+			}
+			if tt.err != nil {
+				if got, _ := tdr.Lookup("app_error"); got != tt.err.Error() {
+					t.Errorf("app_error = %v, want %v", got, tt.err)
+				}
+			}
+		})
+	}
+}
+
+// fakeServerStream receives count messages and records the sent ones.
+type fakeServerStream struct {
+	grpc.ServerStream
+
+	ctx   context.Context
+	count int
+	sent  []interface{}
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	if s.count == 0 {
+		return io.EOF
+	}
+
+	s.count--
+	return nil
+}
+
+func (s *fakeServerStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tl := logger.NewTestLogger()
+	interceptor := logger.NewStreamServerInterceptor(logger.GRPCInterceptorOption{SkipMethods: []string{"/grpc.health.v1.Health/Watch"}})
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		var msg struct{}
+		for stream.RecvMsg(&msg) == nil {
+		}
+
+		stream.SendMsg(map[string]int{"total": 2})
+		return nil
+	}
+
+	ss := &fakeServerStream{ctx: context.Background(), count: 2}
+	if err := interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/orders.Orders/Upload"}, handler); err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+
+	health := &fakeServerStream{ctx: context.Background()}
+	interceptor(nil, health, &grpc.StreamServerInfo{FullMethod: "/grpc.health.v1.Health/Watch"}, handler)
+
+	tdrs := tl.TDRs()
+	if len(tdrs) != 1 {
+		t.Fatalf("TDR records = %d, want 1, skipped methods are not logged", len(tdrs))
+	}
+	if got, _ := tdrs[0].Lookup("app_data.stream_received"); got != float64(2) {
+		t.Errorf("app_data.stream_received = %v, want 2", got)
+	}
+	if got, _ := tdrs[0].Lookup("app_data.stream_sent"); got != float64(1) {
+		t.Errorf("app_data.stream_sent = %v, want 1", got)
+	}
+
+	if got := tl.Entries().FilterMessageSnippet("[gRPC Stream Recv]"); len(got) != 2 {
+		t.Errorf("received message records = %d, want 2", len(got))
+	}
+}
+
+func TestUnaryServerInterceptorMasksCredentialMetadata(t *testing.T) {
+	tl := logger.NewTestLogger()
+	interceptor := logger.NewUnaryServerInterceptor(logger.GRPCInterceptorOption{SensitiveMetadata: []string{"X-Signature"}})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", "Bearer secret-token",
+		"cookie", "session=secret-session",
+		"x-signature", "secret-signature",
+		"x-request-id", "req-1",
+	))
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Get"}
+
+	interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		// The handler still sees the credentials
+		md, _ := metadata.FromIncomingContext(ctx)
+		if got := md.Get("authorization"); len(got) != 1 || got[0] != "Bearer secret-token" {
+			t.Errorf("handler got authorization %v", got)
+		}
+		return nil, nil
+	})
+
+	tdrs := tl.TDRs()
+	if len(tdrs) != 1 {
+		t.Fatalf("TDR records = %d, want 1", len(tdrs))
+	}
+
+	for _, key := range []string{"authorization", "cookie", "x-signature"} {
+		got, _ := tdrs[0].Lookup("app_request_header." + key)
+		if !reflect.DeepEqual(got, []interface{}{"******"}) {
+			t.Errorf("%s = %v, want it masked", key, got)
+		}
+	}
+	if got, _ := tdrs[0].Lookup("app_request_header.x-request-id"); !reflect.DeepEqual(got, []interface{}{"req-1"}) {
+		t.Errorf("x-request-id = %v, want it logged", got)
+	}
+	logtest.AssertNotLogged(t, tl.Entries(), "secret-")
+}
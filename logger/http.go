@@ -0,0 +1,271 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultMaxBodySize = 64 * 1024
+
+// credentialHeaders are the request headers logged masked by default.
+var credentialHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// NewHTTPMiddleware returns a middleware that injects the logger Context of
+// every request and logs its TDR once the request completes. Request and
+// response bodies are captured up to MaxBodySize while the handler reads and
+// writes them, unless the handler sets the response with SetResponse. A
+// panicking handler is logged with a 500 response before the panic goes on.
+// Credential headers, such as Authorization and Cookie, are logged masked.
+func NewHTTPMiddleware(opt HTTPMiddlewareOption) func(http.Handler) http.Handler {
+	maxBodySize := opt.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+
+	skipPaths := toSet(opt.SkipPaths)
+	sensitiveHeaders := headerSet(credentialHeaders, opt.SensitiveHeaders)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skipPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+
+			// Keep whatever an outer middleware already injected
+			ctxLogger := ExtractCtx(r.Context())
+			ctxLogger.ServiceName = valueOr(opt.ServiceName, ctxLogger.ServiceName)
+			ctxLogger.ServiceVersion = valueOr(opt.ServiceVersion, ctxLogger.ServiceVersion)
+			ctxLogger.Tag = valueOr(opt.Tag, ctxLogger.Tag)
+			if opt.ServicePort != 0 {
+				ctxLogger.ServicePort = opt.ServicePort
+			}
+			ctxLogger.ReqMethod = r.Method
+			ctxLogger.ReqURI = r.URL.RequestURI()
+			ctxLogger.ReqHeader = maskHeaders(r.Header.Clone(), sensitiveHeaders)
+
+			// Only loggable request bodies are captured, while the handler reads them
+			reqBody := &bodyCapture{limit: maxBodySize}
+			reqLoggable := isLoggableContentType(r.Header.Get("Content-Type"))
+			if r.Body != nil && r.Body != http.NoBody {
+				if reqLoggable {
+					r.Body = &teeReadCloser{Reader: io.TeeReader(r.Body, reqBody), Closer: r.Body}
+				} else {
+					reqBody.unsupported = true
+				}
+			}
+
+			rw := &responseCapture{
+				ResponseWriter: w,
+				body:           &bodyCapture{limit: maxBodySize},
+			}
+
+			ctx := InjectCtx(r.Context(), ctxLogger)
+
+			defer func() {
+				recovered := recover()
+
+				code, err := rw.statusCode(), error(nil)
+				if recovered != nil {
+					code, err = http.StatusInternalServerError, fmt.Errorf("panic: %v", recovered)
+				}
+
+				rw.body.unsupported = !isLoggableContentType(rw.Header().Get("Content-Type"))
+
+				holderFromCtx(ctx).complete(reqBody.value(), rw.body.value(), code, time.Since(start).String(), err)
+
+				if l := FromContext(ctx); l != nil {
+					l.TDR(ctx)
+				}
+
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
+
+// isLoggableContentType reports whether a body of this content type is text
+// worth logging. Content types skipped by IsSkipPrintLog are not, except the
+// structured ones that are logged as data. Empty content type is assumed to
+// be text.
+func isLoggableContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	if mediaType == "application/x-www-form-urlencoded" ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") {
+		return true
+	}
+
+	return !IsSkipPrintLog("Content-Type: " + mediaType)
+}
+
+// headerSet returns the lowercase names of the given headers.
+func headerSet(lists ...[]string) map[string]bool {
+	set := make(map[string]bool)
+	for _, list := range lists {
+		for _, name := range list {
+			set[strings.ToLower(name)] = true
+		}
+	}
+
+	return set
+}
+
+// maskHeaders masks the values of the sensitive headers of a copied header
+// and returns it.
+func maskHeaders[H ~map[string][]string](header H, sensitive map[string]bool) H {
+	for name, values := range header {
+		if !sensitive[strings.ToLower(name)] {
+			continue
+		}
+
+		masked := make([]string, len(values))
+		for i := range masked {
+			masked[i] = maskedValue
+		}
+		header[name] = masked
+	}
+
+	return header
+}
+
+func valueOr(val, fallback string) string {
+	if val == "" {
+		return fallback
+	}
+
+	return val
+}
+
+// bodyCapture keeps the first limit bytes written to it.
+type bodyCapture struct {
+	buf         bytes.Buffer
+	limit       int
+	truncated   bool
+	unsupported bool
+}
+
+func (b *bodyCapture) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+			b.truncated = true
+		} else {
+			b.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		b.truncated = true
+	}
+
+	return len(p), nil
+}
+
+// value returns the captured body to log. Bodies that are not loggable are
+// replaced, truncated bodies are logged as plain string.
+func (b *bodyCapture) value() interface{} {
+	if b.unsupported {
+		return unsupportedData
+	}
+
+	if b.buf.Len() == 0 {
+		return nil
+	}
+
+	if b.truncated {
+		return b.buf.String() + "...<truncated>"
+	}
+
+	return b.buf.String()
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseCapture records the status code and body written by the handler.
+type responseCapture struct {
+	http.ResponseWriter
+	code int
+	body *bodyCapture
+}
+
+func (w *responseCapture) WriteHeader(statusCode int) {
+	if w.code == 0 {
+		w.code = statusCode
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseCapture) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseCapture) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection over to the handler, such as for a WebSocket.
+// The TDR of a hijacked request reports 101 unless a status was written.
+func (w *responseCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("logger: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err == nil && w.code == 0 {
+		w.code = http.StatusSwitchingProtocols
+	}
+
+	return conn, buf, err
+}
+
+func (w *responseCapture) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode returns the written status code, handlers writing nothing
+// respond with 200.
+func (w *responseCapture) statusCode() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
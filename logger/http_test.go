@@ -0,0 +1,234 @@
+package logger_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"github.com/insaneadinesia/gobang/logger/logtest"
+	"go.uber.org/zap/zapcore"
+)
+
+func serve(mw func(http.Handler) http.Handler, handler http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	mw(handler).ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestHTTPMiddlewareTDR(t *testing.T) {
+	tl := logger.NewTestLogger(logger.Option{
+		IsEnable:            true,
+		EnableMaskingFields: true,
+		MaskingFields:       []string{"password"},
+	})
+	mw := logger.NewHTTPMiddleware(logger.HTTPMiddlewareOption{ServiceName: "orders"})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?id=1", strings.NewReader(`{"user":"jane","password":"plain-password"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	serve(mw, func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		body.ReadFrom(r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"created"}`))
+	}, req)
+
+	tdrs := tl.TDRs()
+	if len(tdrs) != 1 {
+		t.Fatalf("TDR records = %d, want 1", len(tdrs))
+	}
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{path: "app_name", want: "orders"},
+		{path: "app_method", want: http.MethodPost},
+		{path: "app_uri", want: "/orders?id=1"},
+		{path: "app_response_code", want: float64(http.StatusCreated)},
+		{path: "app_request.user", want: "jane"},
+		{path: "app_response.status", want: "created"},
+	}
+	for _, tt := range tests {
+		if got, _ := tdrs[0].Lookup(tt.path); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	if tdrs[0].Level != zapcore.InfoLevel {
+		t.Errorf("TDR level = %v, want info", tdrs[0].Level)
+	}
+	logtest.AssertMasked(t, tdrs[0], "app_request.password")
+	logtest.AssertNotLogged(t, tl.Entries(), "plain-password")
+}
+
+func TestHTTPMiddlewareUnsupportedBody(t *testing.T) {
+	tl := logger.NewTestLogger()
+	mw := logger.NewHTTPMiddleware(logger.HTTPMiddlewareOption{})
+
+	req := httptest.NewRequest(http.MethodPost, "/avatar", bytes.NewReader([]byte{0x89, 'P', 'N', 'G'}))
+	req.Header.Set("Content-Type", "image/png")
+
+	serve(mw, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte{0x00, 0x01})
+	}, req)
+
+	tdr := tl.TDRs()[0]
+	for _, path := range []string{"app_request", "app_response"} {
+		if got, _ := tdr.Lookup(path); got != "<unsupported data>" {
+			t.Errorf("%s = %v, want <unsupported data>", path, got)
+		}
+	}
+}
+
+func TestHTTPMiddlewarePanic(t *testing.T) {
+	tl := logger.NewTestLogger()
+	mw := logger.NewHTTPMiddleware(logger.HTTPMiddlewareOption{})
+
+	defer func() {
+		if recovered := recover(); recovered != "boom" {
+			t.Fatalf("recovered %v, want the handler panic", recovered)
+		}
+
+		tdrs := tl.TDRs()
+		if len(tdrs) != 1 {
+			t.Fatalf("TDR records = %d, want 1", len(tdrs))
+		}
+		if got, _ := tdrs[0].Lookup("app_response_code"); got != float64(http.StatusInternalServerError) {
+			t.Errorf("app_response_code = %v, want 500", got)
+		}
+		if got, _ := tdrs[0].Lookup("app_error"); got != "panic: boom" {
+			t.Errorf("app_error = %v, want the panic", got)
+		}
+		if tdrs[0].Level != zapcore.ErrorLevel {
+			t.Errorf("TDR level = %v, want error", tdrs[0].Level)
+		}
+	}()
+
+	serve(mw, func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}, httptest.NewRequest(http.MethodGet, "/panic", nil))
+}
+
+func TestHTTPMiddlewareSetResponse(t *testing.T) {
+	tl := logger.NewTestLogger()
+	mw := logger.NewHTTPMiddleware(logger.HTTPMiddlewareOption{SkipPaths: []string{"/health"}})
+
+	serve(mw, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("streamed body"))
+		logger.SetResponse(r.Context(), http.StatusAccepted, map[string]string{"status": "queued"})
+	}, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+
+	serve(mw, func(w http.ResponseWriter, r *http.Request) {}, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	tdrs := tl.TDRs()
+	if len(tdrs) != 1 {
+		t.Fatalf("TDR records = %d, want 1, skipped paths are not logged", len(tdrs))
+	}
+	if got, _ := tdrs[0].Lookup("app_response_code"); got != float64(http.StatusAccepted) {
+		t.Errorf("app_response_code = %v, want 202", got)
+	}
+	if got, _ := tdrs[0].Lookup("app_response.status"); got != "queued" {
+		t.Errorf("app_response.status = %v, want queued", got)
+	}
+}
+
+func TestHTTPMiddlewareMasksCredentialHeaders(t *testing.T) {
+	tl := logger.NewTestLogger()
+	mw := logger.NewHTTPMiddleware(logger.HTTPMiddlewareOption{SensitiveHeaders: []string{"X-Signature"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("Cookie", "session=secret-session")
+	req.Header.Set("X-Signature", "secret-signature")
+	req.Header.Set("Accept", "application/json")
+
+	serve(mw, func(w http.ResponseWriter, r *http.Request) {
+		// The handler still sees the credentials
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			t.Errorf("handler got Authorization %q", r.Header.Get("Authorization"))
+		}
+	}, req)
+
+	tdrs := tl.TDRs()
+	if len(tdrs) != 1 {
+		t.Fatalf("TDR records = %d, want 1", len(tdrs))
+	}
+
+	for _, header := range []string{"Authorization", "Cookie", "X-Signature"} {
+		got, _ := tdrs[0].Lookup("app_request_header." + header)
+		if !reflect.DeepEqual(got, []interface{}{"******"}) {
+			t.Errorf("%s = %v, want it masked", header, got)
+		}
+	}
+	if got, _ := tdrs[0].Lookup("app_request_header.Accept"); !reflect.DeepEqual(got, []interface{}{"application/json"}) {
+		t.Errorf("Accept = %v, want it logged", got)
+	}
+	logtest.AssertNotLogged(t, tl.Entries(), "secret-")
+}
+
+func TestHTTPMiddlewareForwardsHijack(t *testing.T) {
+	tl := logger.NewTestLogger()
+	mw := logger.NewHTTPMiddleware(logger.HTTPMiddlewareOption{})
+
+	server := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "not a hijacker", http.StatusInternalServerError)
+			return
+		}
+
+		conn, buf, err := hijacker.Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+		buf.Flush()
+	})))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "GET /ws HTTP/1.1\r\nHost: example\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status = %d, want 101", resp.StatusCode)
+	}
+
+	// The TDR is logged once the handler returns
+	deadline := time.Now().Add(time.Second)
+	for len(tl.TDRs()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	tdrs := tl.TDRs()
+	if len(tdrs) != 1 {
+		t.Fatalf("TDR records = %d, want 1", len(tdrs))
+	}
+	if got, _ := tdrs[0].Lookup("app_response_code"); got != float64(http.StatusSwitchingProtocols) {
+		t.Errorf("app_response_code = %v, want 101", got)
+	}
+}
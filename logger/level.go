@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ctxKeyComponent is the context key for the logging component name.
+type ctxKeyComponent struct{}
+
+// WithComponent marks every log written with the returned context as belonging
+// to component, so its level can be overridden with SetComponentLevel.
+func WithComponent(parent context.Context, component string) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	return context.WithValue(parent, ctxKeyComponent{}, component)
+}
+
+// componentFromCtx returns the component name of the context, if any.
+func componentFromCtx(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	component, _ := ctx.Value(ctxKeyComponent{}).(string)
+	return component
+}
+
+// levelController holds the base level and the per component overrides.
+type levelController struct {
+	level      zap.AtomicLevel
+	mu         sync.RWMutex
+	components map[string]zapcore.Level
+}
+
+func newLevelController(opt Option) *levelController {
+	// With sinks, the default level lets every sink get what it asks for
+	level := zapcore.InfoLevel
+	for i, sink := range opt.Sinks {
+		if sinkLevel := parseLevel(sink.Level, zapcore.DebugLevel); i == 0 || sinkLevel < level {
+			level = sinkLevel
+		}
+	}
+
+	l := &levelController{
+		level:      zap.NewAtomicLevelAt(parseLevel(opt.Level, level)),
+		components: make(map[string]zapcore.Level),
+	}
+
+	for component, level := range opt.ComponentLevels {
+		l.components[component] = parseLevel(level, l.level.Level())
+	}
+
+	return l
+}
+
+// Enabled reports whether a log at level for component should be written.
+func (l *levelController) Enabled(component string, level zapcore.Level) bool {
+	return level >= l.ComponentLevel(component)
+}
+
+func (l *levelController) SetLevel(level zapcore.Level) {
+	l.level.SetLevel(level)
+}
+
+func (l *levelController) Level() zapcore.Level {
+	return l.level.Level()
+}
+
+func (l *levelController) SetComponentLevel(component string, level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.components[component] = level
+}
+
+// ComponentLevel returns the override of component, or the base level when
+// it has none. Components named "payment.refund" fall back to the override of
+// "payment".
+func (l *levelController) ComponentLevel(component string) zapcore.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for component != "" {
+		if level, ok := l.components[component]; ok {
+			return level
+		}
+
+		dot := strings.LastIndexByte(component, '.')
+		if dot < 0 {
+			break
+		}
+		component = component[:dot]
+	}
+
+	return l.level.Level()
+}
+
+// parseLevel parses a level name, returning def when it is empty or unknown.
+func parseLevel(text string, def zapcore.Level) zapcore.Level {
+	// ParseLevel reads an empty text as info
+	if text == "" {
+		return def
+	}
+
+	level, err := zapcore.ParseLevel(text)
+	if err != nil {
+		return def
+	}
+
+	return level
+}
+
+// levelPayload is the request and response body of the level handler.
+type levelPayload struct {
+	Level     string `json:"level"`
+	Component string `json:"component,omitempty"`
+}
+
+// NewLevelHandler returns an HTTP handler to inspect and change the level of l
+// at runtime. GET returns the current level, PUT changes it. Both accept an
+// optional component, as query parameter for GET and in the body for PUT:
+//
+//	curl -X PUT -d '{"level":"debug","component":"payment"}' localhost:8080/log/level
+func NewLevelHandler(l Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			component := r.URL.Query().Get("component")
+			json.NewEncoder(w).Encode(levelPayload{
+				Level:     l.ComponentLevel(component).String(),
+				Component: component,
+			})
+		case http.MethodPut:
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeLevelError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+
+			level, err := zapcore.ParseLevel(req.Level)
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			if req.Component != "" {
+				l.SetComponentLevel(req.Component, level)
+			} else {
+				l.SetLevel(level)
+			}
+
+			json.NewEncoder(w).Encode(req)
+		default:
+			writeLevelError(w, http.StatusMethodNotAllowed, "only GET and PUT are supported")
+		}
+	})
+}
+
+func writeLevelError(w http.ResponseWriter, statusCode int, message string) {
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		text string
+		def  zapcore.Level
+		want zapcore.Level
+	}{
+		{text: "warn", def: zapcore.DebugLevel, want: zapcore.WarnLevel},
+		{text: "ERROR", def: zapcore.DebugLevel, want: zapcore.ErrorLevel},
+		{text: "", def: zapcore.DebugLevel, want: zapcore.DebugLevel},
+		{text: "", def: zapcore.ErrorLevel, want: zapcore.ErrorLevel},
+		{text: "verbose", def: zapcore.WarnLevel, want: zapcore.WarnLevel},
+	}
+
+	for _, tt := range tests {
+		if got := parseLevel(tt.text, tt.def); got != tt.want {
+			t.Errorf("parseLevel(%q, %v) = %v, want %v", tt.text, tt.def, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"context"
+
+	"go.uber.org/zap/zapcore"
 )
 
 var Log Logger
@@ -15,6 +17,25 @@ type Logger interface {
 	Fatal(ctx context.Context, message string, fields ...interface{})
 	Panic(ctx context.Context, message string, fields ...interface{})
 	TDR(ctx context.Context)
+
+	// With returns a child logger adding fields to every record. Named returns
+	// a child logger of the given component, nested names are joined with a
+	// dot. Children share the outputs, levels and masking of their parent.
+	With(fields ...Field) Logger
+	Named(name string) Logger
+
+	// SetLevel changes the minimum level written, Level returns it.
+	SetLevel(level zapcore.Level)
+	Level() zapcore.Level
+	// SetComponentLevel overrides the level of a single component, ComponentLevel
+	// returns the effective level of a component.
+	SetComponentLevel(component string, level zapcore.Level)
+	ComponentLevel(component string) zapcore.Level
+
+	// Sync flushes buffered records. Close flushes them and releases the
+	// outputs, the logger must not be used afterwards.
+	Sync() error
+	Close() error
 }
 
 // Field represents a key-value pair for logging fields.
@@ -23,6 +44,21 @@ type Field struct {
 	Val interface{}
 }
 
+// F creates a named field, logged under its own key instead of message_<index>.
+func F(key string, val interface{}) Field {
+	return Field{Key: key, Val: val}
+}
+
+// Err creates a field holding the error message under the "error" key. A nil
+// err gives an empty field, which is not logged.
+func Err(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+
+	return Field{Key: "error", Val: err.Error()}
+}
+
 // ctxKeyLogger is a type for context keys to avoid collisions.
 type ctxKeyLogger struct{}
 
@@ -46,25 +82,26 @@ type Context struct {
 	RespTime       string                 `json:"app_exec_time,omitempty"`
 }
 
-// InjectCtx injects the logger context into the parent context.
+// InjectCtx returns a copy of parent holding ctx. The returned context gets
+// its own request-scoped holder, seeded with the error and response recorded
+// on parent, so injecting never changes what parent holds. SetResponse,
+// AddData and SetError update the holder in place, they are visible to
+// whoever logs the TDR from the returned context.
 func InjectCtx(parent context.Context, ctx Context) context.Context {
 	if parent == nil {
 		return InjectCtx(context.Background(), ctx)
 	}
 
-	return context.WithValue(parent, ctxKey, ctx)
-}
-
-// ExtractCtx extracts the logger context from the given context.
-func ExtractCtx(ctx context.Context) Context {
-	if ctx == nil {
-		return Context{}
+	holder := newContextHolder(ctx)
+	if parentHolder := holderFromCtx(parent); parentHolder != nil {
+		holder.err, holder.respSet = parentHolder.outcome()
 	}
 
-	val, ok := ctx.Value(ctxKey).(Context)
-	if !ok {
-		return Context{}
-	}
+	return context.WithValue(parent, ctxKey, holder)
+}
 
+// ExtractCtx extracts a snapshot of the logger context from the given context.
+func ExtractCtx(ctx context.Context) Context {
+	val, _ := extractCtxWithError(ctx)
 	return val
 }
@@ -3,7 +3,10 @@ package logger
 import (
 	"context"
 	"encoding/json"
-	"reflect"
+	"errors"
+	"io"
+	"runtime"
+	"slices"
 
 	"github.com/spf13/cast"
 	"go.uber.org/zap"
@@ -13,24 +16,26 @@ import (
 
 // defaultLogger is a logger implementation using zap.
 type defaultLogger struct {
-	zapLogger     *zap.Logger
-	enableMasking bool
-	maskingFields map[string]bool
+	zapLogger        *zap.Logger
+	closers          []io.Closer
+	levels           *levelController
+	masker           *masker
+	enableSpanEvents bool
+	spanEventLevel   zapcore.Level
+
+	// name and bound are set on child loggers by Named and With. Children
+	// share the outputs of their root logger, only the root closes them.
+	name  string
+	bound []zap.Field
+	child bool
 }
 
 // NewLogger creates a new logger based on provided options.
 func NewLogger(opt Option) Logger {
-	// Assign masking fields to global variable
-	maskingFields := make(map[string]bool)
-	for _, fieldName := range opt.MaskingFields {
-		maskingFields[fieldName] = true
-	}
-
-	logger := &defaultLogger{
-		zapLogger:     NewZapLogger(opt),
-		enableMasking: opt.EnableMaskingFields,
-		maskingFields: maskingFields,
-	}
+	// Levels are checked by the level controller, so the core must let everything through
+	zapLogger, closers := newZapLogger(opt, zap.NewAtomicLevelAt(zap.DebugLevel))
+	logger := newDefaultLogger(opt, zapLogger)
+	logger.closers = closers
 
 	// Assign to global variable, so it can be called in all file without injecting depedency
 	Log = logger
@@ -38,6 +43,27 @@ func NewLogger(opt Option) Logger {
 	return logger
 }
 
+// callerSkip is the number of defaultLogger frames between the caller and zap,
+// such as Info and log.
+const callerSkip = 2
+
+// tdrMessage is the message of TDR records.
+const tdrMessage = "TDR"
+
+// unsupportedData replaces data that cannot be logged, such as binary bodies.
+const unsupportedData = "<unsupported data>"
+
+// newDefaultLogger creates a defaultLogger writing to zapLogger.
+func newDefaultLogger(opt Option, zapLogger *zap.Logger) *defaultLogger {
+	return &defaultLogger{
+		zapLogger:        zapLogger.WithOptions(zap.AddCallerSkip(callerSkip)),
+		levels:           newLevelController(opt),
+		masker:           newMasker(opt),
+		enableSpanEvents: opt.EnableSpanEvents,
+		spanEventLevel:   parseLevel(opt.SpanEventLevel, zapcore.InfoLevel),
+	}
+}
+
 // Debug logs a debug message with context and fields.
 func (d *defaultLogger) Debug(ctx context.Context, message string, details ...interface{}) {
 	d.log(ctx, zap.DebugLevel, message, details...)
@@ -68,37 +94,194 @@ func (d *defaultLogger) Panic(ctx context.Context, message string, details ...in
 	d.log(ctx, zap.PanicLevel, message, details...)
 }
 
+// TDR logs the transaction data record of the request held by ctx. Its level
+// follows the response code and error of the request.
 func (d *defaultLogger) TDR(ctx context.Context) {
-	zapLogs := []zap.Field{}
+	// Called through tdr to keep the same callerSkip as log
+	d.tdr(ctx)
+}
+
+func (d *defaultLogger) tdr(ctx context.Context) {
+	ctxVal, err := extractCtxWithError(ctx)
+
+	level := tdrLevel(ctxVal, err)
+	if !d.levels.Enabled(d.component(ctx), level) {
+		return
+	}
+
+	// Trace context is passed as regular fields, so encoders get every field in order
+	zapLogs := TraceContext(ctx)
+
+	zapLogs = append(zapLogs, d.formatTDRLog(ctxVal, err)...)
+	d.zapLogger.Log(level, tdrMessage, zapLogs...)
+}
 
-	traceContextFields := TraceContext(ctx)
+// With returns a child logger adding fields to every record. Fields are
+// formatted and masked once, when the child is created.
+func (d *defaultLogger) With(fields ...Field) Logger {
+	child := *d
+	child.child = true
+	child.bound = slices.Clip(d.bound)
+	for _, field := range fields {
+		if field.Key != "" {
+			child.bound = append(child.bound, d.formatField(field.Key, field.Val))
+		}
+	}
 
-	zapLogs = append(zapLogs, d.formatTDRLog(ctx)...)
-	d.zapLogger.With(traceContextFields...).Log(zap.InfoLevel, "TDR", zapLogs...)
+	return &child
+}
+
+// Named returns a child logger of the given component. Its records carry the
+// app_component field and follow the component level.
+func (d *defaultLogger) Named(name string) Logger {
+	child := *d
+	child.child = true
+	child.name = name
+	if d.name != "" {
+		child.name = d.name + "." + name
+	}
+
+	return &child
+}
+
+// component returns the component a record belongs to, the name of the
+// logger takes precedence over the one of the context.
+func (d *defaultLogger) component(ctx context.Context) string {
+	if d.name != "" {
+		return d.name
+	}
+
+	return componentFromCtx(ctx)
+}
+
+// SetLevel changes the minimum level written.
+func (d *defaultLogger) SetLevel(level zapcore.Level) {
+	d.levels.SetLevel(level)
+}
+
+// Level returns the minimum level written.
+func (d *defaultLogger) Level() zapcore.Level {
+	return d.levels.Level()
+}
+
+// SetComponentLevel overrides the minimum level written for a component.
+func (d *defaultLogger) SetComponentLevel(component string, level zapcore.Level) {
+	d.levels.SetComponentLevel(component, level)
+}
+
+// ComponentLevel returns the minimum level written for a component.
+func (d *defaultLogger) ComponentLevel(component string) zapcore.Level {
+	return d.levels.ComponentLevel(component)
+}
+
+// Sync flushes buffered records to the outputs.
+func (d *defaultLogger) Sync() error {
+	return d.zapLogger.Sync()
+}
+
+// Close flushes buffered records and closes the outputs. Closing a child
+// logger only flushes, the outputs stay open for its root logger.
+func (d *defaultLogger) Close() error {
+	if d.child {
+		return d.zapLogger.Sync()
+	}
+
+	errs := []error{d.zapLogger.Sync()}
+	for _, closer := range d.closers {
+		errs = append(errs, closer.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// DroppedRecords returns the number of records dropped by the async writers
+// and the socket sinks.
+func (d *defaultLogger) DroppedRecords() uint64 {
+	var dropped uint64
+	for _, closer := range d.closers {
+		if counter, ok := closer.(interface{ Dropped() uint64 }); ok {
+			dropped += counter.Dropped()
+		}
+	}
+
+	return dropped
 }
 
 func (d *defaultLogger) log(ctx context.Context, level zapcore.Level, message string, details ...interface{}) {
-	zapLogs := []zap.Field{}
+	if zapLogs, ok := d.recordFields(ctx, level, message, details...); ok {
+		d.zapLogger.Log(level, message, zapLogs...)
+	}
+}
 
-	traceContextFields := TraceContext(ctx)
+// logAt logs like log, reporting the function at pc as the caller instead of
+// walking the stack. It serves records whose caller is already known, such as
+// those of log/slog.
+func (d *defaultLogger) logAt(ctx context.Context, level zapcore.Level, pc uintptr, message string, details ...interface{}) {
+	zapLogs, ok := d.recordFields(ctx, level, message, details...)
+	if !ok {
+		return
+	}
+
+	ce := d.zapLogger.Check(level, message)
+	if ce == nil {
+		return
+	}
+
+	// Caller is only defined when enabled
+	if ce.Caller.Defined && pc != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		ce.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       pc,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+
+	ce.Write(zapLogs...)
+}
+
+// recordFields returns the fields of a record, or false when its level is
+// disabled. The detail fields are added to the active span as an event.
+func (d *defaultLogger) recordFields(ctx context.Context, level zapcore.Level, message string, details ...interface{}) ([]zap.Field, bool) {
+	// Panic and fatal records always reach zap, which panics or exits after
+	// writing them even when the level is disabled
+	if level < zapcore.DPanicLevel && !d.levels.Enabled(d.component(ctx), level) {
+		return nil, false
+	}
+
+	// Trace context is passed as regular fields, so encoders get every field in order
+	zapLogs := TraceContext(ctx)
 
 	fields := d.formatToField(details...)
 	zapLogs = append(zapLogs, d.formatLogs(ctx, fields...)...)
-	d.zapLogger.With(traceContextFields...).Log(level, message, zapLogs...)
+
+	// formatLogs appends the detail fields last, only those belong to the span event
+	d.addSpanEvent(ctx, level, message, zapLogs[len(zapLogs)-len(fields):])
+
+	return zapLogs, true
 }
 
-func (d *defaultLogger) formatToField(details ...interface{}) (logRecord []Field) {
-	for index, msg := range details {
-		logRecord = append(logRecord, Field{
-			Key: "message_" + cast.ToString(index),
-			Val: msg,
-		})
+func (d *defaultLogger) formatToField(details ...interface{}) (logRecord []zap.Field) {
+	index := 0
+	for _, msg := range details {
+		// Typed field keep its own key, Err(nil) has none and is left out
+		if field, ok := msg.(Field); ok {
+			if field.Key != "" {
+				logRecord = append(logRecord, d.formatField(field.Key, field.Val))
+			}
+			continue
+		}
+
+		logRecord = append(logRecord, d.formatLog("message_"+cast.ToString(index), msg))
+		index++
 	}
 
 	return
 }
 
-func (d *defaultLogger) formatLogs(ctx context.Context, fields ...Field) (logRecord []zap.Field) {
+func (d *defaultLogger) formatLogs(ctx context.Context, fields ...zap.Field) (logRecord []zap.Field) {
 	ctxVal := ExtractCtx(ctx)
 
 	// Add global value from context that must be exist on all logs!
@@ -114,16 +297,12 @@ func (d *defaultLogger) formatLogs(ctx context.Context, fields ...Field) (logRec
 		logRecord = append(logRecord, zap.Any("app_data", ctxVal.AdditionalData))
 	}
 
-	for _, field := range fields {
-		logRecord = append(logRecord, d.formatLog(field.Key, field.Val))
-	}
+	logRecord = append(logRecord, d.boundFields()...)
 
-	return
+	return append(logRecord, fields...)
 }
 
-func (d *defaultLogger) formatTDRLog(ctx context.Context) (logRecord []zap.Field) {
-	ctxVal := ExtractCtx(ctx)
-
+func (d *defaultLogger) formatTDRLog(ctxVal Context, err error) (logRecord []zap.Field) {
 	// Add global value from context that must be exist on all logs!
 	logRecord = []zap.Field{
 		zap.String("app_name", ctxVal.ServiceName),
@@ -140,12 +319,56 @@ func (d *defaultLogger) formatTDRLog(ctx context.Context) (logRecord []zap.Field
 	logRecord = append(logRecord, d.formatLog("app_request", ctxVal.ReqBody))
 	logRecord = append(logRecord, d.formatLog("app_response", ctxVal.RespBody))
 
+	if ctxVal.Error != "" {
+		logRecord = append(logRecord, zap.Any("app_error", d.maskData(ctxVal.Error)))
+	}
+
+	// Every error of a wrapped or joined chain, with their stack trace if any
+	if chain := errorChain(err); len(chain) > 1 || (len(chain) == 1 && chain[0].StackTrace != "") {
+		logRecord = append(logRecord, zap.Any("app_error_chain", d.maskData(chain)))
+	}
+
 	// Add additional data that available across all log, such as user_id
 	if ctxVal.AdditionalData != nil {
 		logRecord = append(logRecord, zap.Any("app_data", ctxVal.AdditionalData))
 	}
 
-	return logRecord
+	return append(logRecord, d.boundFields()...)
+}
+
+// boundFields returns the fields added by Named and With.
+func (d *defaultLogger) boundFields() []zap.Field {
+	if d.name == "" {
+		return d.bound
+	}
+
+	return append([]zap.Field{zap.String("app_component", d.name)}, d.bound...)
+}
+
+// formatField formats a field created with F. Strings are logged as they are,
+// unlike the positional details they are not decoded when they look like
+// JSON. The value is masked as the value of key.
+func (d *defaultLogger) formatField(key string, val interface{}) zap.Field {
+	switch v := val.(type) {
+	case nil:
+		return d.formatLog(key, v)
+	case string:
+		if IsSkipPrintLog(v) {
+			return zap.String(key, unsupportedData)
+		}
+	case []byte:
+		// Bytes usually hold a payload, decoded like the positional details
+		var data interface{}
+		if err := json.Unmarshal(v, &data); err == nil {
+			val = data
+		} else if IsSkipPrintLog(string(v)) {
+			return zap.String(key, unsupportedData)
+		} else {
+			val = string(v)
+		}
+	}
+
+	return zap.Any(key, d.masker.maskField(key, val))
 }
 
 func (d *defaultLogger) formatLog(key string, msg interface{}) (logRecord zap.Field) {
@@ -157,12 +380,10 @@ func (d *defaultLogger) formatLog(key string, msg interface{}) (logRecord zap.Fi
 	// Detect the message data type then convert it to json if possible
 	p, ok := msg.(proto.Message)
 	if ok {
-		b, _ := json.Marshal(p)
-
-		var data interface{}
+		data, err := d.masker.protoToData(p)
 
 		// if error happened, just print the original message
-		if err := json.Unmarshal(b, &data); err != nil {
+		if err != nil {
 			logRecord = zap.Any(key, p)
 			return
 		}
@@ -178,10 +399,10 @@ func (d *defaultLogger) formatLog(key string, msg interface{}) (logRecord zap.Fi
 		if err := json.Unmarshal([]byte(str), &data); err != nil {
 			if IsSkipPrintLog(str) {
 				// Replace string if data is unsupported to log
-				logRecord = zap.Any(key, "<unsupported data>")
+				logRecord = zap.Any(key, unsupportedData)
 			} else {
 				// Fallback: Just print the original message
-				logRecord = zap.Any(key, str)
+				logRecord = zap.Any(key, d.maskData(str))
 			}
 
 			return
@@ -200,10 +421,10 @@ func (d *defaultLogger) formatLog(key string, msg interface{}) (logRecord zap.Fi
 		if err := json.Unmarshal([]byte(str), &data); err != nil {
 			if IsSkipPrintLog(str) {
 				// Replace string if data is unsupported to log
-				logRecord = zap.Any(key, "<unsupported data>")
+				logRecord = zap.Any(key, unsupportedData)
 			} else {
 				// Fallback: Just print the original message
-				logRecord = zap.Any(key, str)
+				logRecord = zap.Any(key, d.maskData(str))
 			}
 
 			return
@@ -218,39 +439,5 @@ func (d *defaultLogger) formatLog(key string, msg interface{}) (logRecord zap.Fi
 }
 
 func (d *defaultLogger) maskData(input interface{}) interface{} {
-	if !d.enableMasking {
-		return input
-	}
-
-	val := reflect.ValueOf(input)
-	maskingFields := d.maskingFields
-
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
-	}
-
-	switch val.Kind() {
-	case reflect.Map:
-		// If it's a map, iterate through the keys and mask sensitive ones
-		for _, key := range val.MapKeys() {
-			// Convert key to string
-			keyStr := key.String()
-			if maskingFields[keyStr] {
-				val.SetMapIndex(key, reflect.ValueOf("******"))
-			} else {
-				val.SetMapIndex(key, reflect.ValueOf(d.maskData(val.MapIndex(key).Interface())))
-			}
-		}
-	case reflect.Struct:
-		// Convert to map string so it can be masking. Somehow struct type is immutable
-		var data map[string]interface{}
-		byData, _ := json.Marshal(input)
-		json.Unmarshal(byData, &data)
-
-		// Replace the input data with new data type
-		input = data
-		d.maskData(data)
-	}
-
-	return input
+	return d.masker.mask(input)
 }
@@ -0,0 +1,151 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"github.com/insaneadinesia/gobang/logger/logtest"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestPanicIgnoresLevel(t *testing.T) {
+	l := logger.NewTestLogger(logger.Option{IsEnable: true, Level: "fatal"})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Panic did not panic while the level is fatal")
+		}
+
+		if got := l.Entries().FilterLevel(zapcore.PanicLevel); len(got) != 1 {
+			t.Errorf("panic records = %d, want 1", len(got))
+		}
+	}()
+
+	l.Info(context.Background(), "dropped")
+	l.Panic(context.Background(), "boom")
+}
+
+func TestChildCloseKeepsOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := logger.NewLogger(logger.Option{
+		File:     logger.FileOption{Path: path},
+		Async:    logger.AsyncOption{Enable: true, FlushInterval: time.Hour},
+		Sampling: logger.SamplingOption{Enable: true},
+	})
+
+	ctx := context.Background()
+	child := l.Named("payment").With(logger.Field{Key: "job_id", Val: "j-1"})
+	child.Info(ctx, "from child")
+	if err := child.Close(); err != nil {
+		t.Fatalf("child Close: %v", err)
+	}
+
+	l.Info(ctx, "from root")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	for _, message := range []string{"from child", "from root"} {
+		if !strings.Contains(string(data), message) {
+			t.Errorf("log file is missing %q: %s", message, data)
+		}
+	}
+}
+
+func TestFieldStringsAreNotDecoded(t *testing.T) {
+	tl := logger.NewTestLogger()
+
+	tl.Info(context.Background(), "order placed",
+		logger.F("order_id", "123"),
+		logger.F("confirmed", "true"),
+		logger.F("coupon", "null"),
+		logger.F("payload", []byte(`{"amount":10}`)),
+		`{"legacy":1}`,
+	)
+
+	entry := tl.Entries()[0]
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{path: "order_id", want: "123"},
+		{path: "confirmed", want: "true"},
+		{path: "coupon", want: "null"},
+		{path: "payload.amount", want: float64(10)},
+		{path: "message_0.legacy", want: float64(1)},
+	}
+
+	for _, tt := range tests {
+		if got, _ := entry.Lookup(tt.path); got != tt.want {
+			t.Errorf("%s = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFieldKeyIsMasked(t *testing.T) {
+	tl := logger.NewTestLogger(logger.Option{
+		IsEnable:            true,
+		EnableMaskingFields: true,
+		MaskingFields:       []string{"password"},
+	})
+
+	tl.Info(context.Background(), "login", logger.F("password", "hunter2"))
+
+	logtest.AssertMasked(t, tl.Entries()[0], "password")
+	logtest.AssertNotLogged(t, tl.Entries(), "hunter2")
+}
+
+func TestErrNilIsNotLogged(t *testing.T) {
+	tl := logger.NewTestLogger()
+
+	tl.Info(context.Background(), "charged", logger.Err(nil))
+	tl.With(logger.Err(nil)).Info(context.Background(), "charged")
+
+	for _, entry := range tl.Entries() {
+		if got, ok := entry.Lookup("error"); ok {
+			t.Errorf("error = %v is logged for a nil error", got)
+		}
+	}
+}
+
+// fileRecords returns the records written to a log file by fn, through a
+// logger created with opt.
+func fileRecords(t *testing.T, opt logger.Option, fn func(l logger.Logger)) []map[string]interface{} {
+	t.Helper()
+
+	opt.File = logger.FileOption{Path: filepath.Join(t.TempDir(), "app.log")}
+	l := logger.NewLogger(opt)
+	fn(l)
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(opt.File.Path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		record := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decode %s: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
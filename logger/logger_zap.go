@@ -1,34 +1,124 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-// NewZapLogger creates a new zap logger based on the provided options.
+// NewZapLogger creates a new zap logger based on the provided options. The
+// files, sockets and background writers it opens are never released, use
+// NewZapLoggerWithClose when the logger does not live as long as the process.
 func NewZapLogger(opt Option) *zap.Logger {
-	var outputPaths, errorOutputPaths []string
+	zapLogger, _ := NewZapLoggerWithClose(opt)
+	return zapLogger
+}
+
+// NewZapLoggerWithClose creates a new zap logger like NewZapLogger, along with
+// a function flushing it and releasing its outputs. The logger must not be
+// used once it is closed.
+func NewZapLoggerWithClose(opt Option) (*zap.Logger, func() error) {
+	zapLogger, closers := newZapLogger(opt, zap.NewAtomicLevelAt(parseLevel(opt.Level, zap.InfoLevel)))
+
+	return zapLogger, func() error {
+		errs := []error{zapLogger.Sync()}
+		for _, closer := range closers {
+			errs = append(errs, closer.Close())
+		}
+
+		return errors.Join(errs...)
+	}
+}
+
+// newZapLogger creates a new zap logger gated by the given level, along with
+// the resources that must be closed when the logger is no longer used.
+func newZapLogger(opt Option, level zap.AtomicLevel) (*zap.Logger, []io.Closer) {
+	var (
+		cores       []zapcore.Core
+		closers     []io.Closer
+		errorOutput = zapcore.AddSync(io.Discard)
+	)
+
+	if opt.IsEnable || len(opt.Sinks) > 0 {
+		errorOutput = zapcore.Lock(zapcore.AddSync(struct{ io.Writer }{os.Stderr}))
+	}
+
+	if len(opt.Sinks) == 0 {
+		var writers []zapcore.WriteSyncer
+
+		// Default path will be shown on console
+		if opt.IsEnable {
+			writers = append(writers, newConsoleWriter(os.Stdout))
+		}
+
+		if opt.File.Path != "" {
+			file := newRotatingFile(opt.File)
+			writers = append(writers, file)
+			closers = append(closers, file)
+		}
+
+		if len(writers) > 0 {
+			sink := SinkOption{Encoding: opt.Encoding, Schema: opt.Schema}
+			color := opt.File.Path == ""
+
+			core, async := newSinkCore(opt, sink, zapcore.NewMultiWriteSyncer(writers...), level, color)
+			cores = append(cores, core)
+			closers = append(async, closers...)
+		}
+	}
+
+	for _, sink := range opt.Sinks {
+		output, sinkClosers, err := newSinkWriter(sink)
+		if err != nil {
+			fmt.Fprintf(errorOutput, "logger: skipping sink %q: %v\n", sink.Output, err)
+			continue
+		}
+
+		sinkLevel := parseLevel(sink.Level, zapcore.DebugLevel)
+		enabler := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= sinkLevel && level.Enabled(l)
+		})
+		color := sink.Output == "stdout" || sink.Output == "stderr"
+
+		core, async := newSinkCore(opt, sink, output, enabler, color)
+		cores = append(cores, core)
+		closers = append(closers, append(async, sinkClosers...)...)
+	}
+
+	core := zapcore.NewNopCore()
+	if len(cores) > 0 {
+		core = zapcore.NewTee(cores...)
+
+		// Summary must be written before the outputs are closed
+		if opt.Sampling.Enable {
+			var summary io.Closer
+			core, summary = newSamplingCore(core, opt.Sampling)
+			closers = append([]io.Closer{summary}, closers...)
+		}
+	}
+
+	zapOpts := []zap.Option{
+		zap.ErrorOutput(errorOutput),
+		zap.WithCaller(opt.EnableCaller),
+	}
 
-	// Default path will be shown on console
-	if opt.IsEnable {
-		outputPaths = []string{"stdout"}
-		errorOutputPaths = []string{"stderr"}
+	// Development mode records stack traces from warn level, production from error level
+	stackTraceLevel := zapcore.ErrorLevel
+	if !opt.Production {
+		zapOpts = append(zapOpts, zap.Development())
+		stackTraceLevel = zapcore.WarnLevel
 	}
 
-	cfg := zap.Config{
-		Level:             zap.NewAtomicLevelAt(zap.InfoLevel),
-		Development:       true,
-		DisableCaller:     true,
-		DisableStacktrace: !opt.EnableStackTrace,
-		Encoding:          "json",
-		EncoderConfig:     getEncoderConfig(),
-		OutputPaths:       outputPaths,
-		ErrorOutputPaths:  errorOutputPaths,
+	if opt.EnableStackTrace {
+		zapOpts = append(zapOpts, zap.AddStacktrace(parseLevel(opt.StackTraceLevel, stackTraceLevel)))
 	}
 
-	return zap.Must(cfg.Build())
+	return zap.New(core, zapOpts...), closers
 }
 
 // getEncoderConfig returns the encoder configuration for zap.
@@ -38,6 +128,7 @@ func getEncoderConfig() zapcore.EncoderConfig {
 	cfg.EncodeDuration = zapcore.MillisDurationEncoder
 	cfg.EncodeTime = timeEncoder
 	cfg.MessageKey = "message"
+	cfg.FunctionKey = "function"
 
 	return cfg
 }
@@ -0,0 +1,40 @@
+// Package logtest provides assertions on the records kept by
+// logger.NewTestLogger.
+package logtest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+)
+
+// AssertMasked fails the test when the value at path of entry is missing or
+// was not masked by any of the masking strategies.
+func AssertMasked(t testing.TB, entry logger.Entry, path string) {
+	t.Helper()
+
+	val, ok := entry.Lookup(path)
+	if !ok {
+		t.Errorf("logger: %q is not logged in %q", path, entry.Message)
+		return
+	}
+
+	str, ok := val.(string)
+	if !ok || !(strings.Contains(str, "*") || strings.HasPrefix(str, "sha256:")) {
+		t.Errorf("logger: %q is not masked in %q: %v", path, entry.Message, val)
+	}
+}
+
+// AssertNotLogged fails the test when secret appears anywhere in entries.
+func AssertNotLogged(t testing.TB, entries logger.Entries, secret string) {
+	t.Helper()
+
+	for _, entry := range entries {
+		data, _ := json.Marshal(entry.Fields)
+		if strings.Contains(entry.Message, secret) || strings.Contains(string(data), secret) {
+			t.Errorf("logger: secret is logged in %q: %s", entry.Message, data)
+		}
+	}
+}
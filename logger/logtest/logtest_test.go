@@ -0,0 +1,76 @@
+package logtest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"github.com/insaneadinesia/gobang/logger/logtest"
+	"go.uber.org/zap/zapcore"
+)
+
+// recorder is a testing.TB recording failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestTestLoggerEntries(t *testing.T) {
+	tl := logger.NewTestLogger()
+	if logger.Log != tl {
+		t.Fatal("NewTestLogger did not assign the global Log")
+	}
+
+	ctx := context.Background()
+	tl.Debug(ctx, "loading order", logger.Field{Key: "order_id", Val: 42})
+	tl.Warn(ctx, "order is late")
+
+	entries := tl.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+
+	if got := entries.FilterLevel(zapcore.WarnLevel).FilterMessage("order is late"); len(got) != 1 {
+		t.Errorf("warn records = %d, want 1", len(got))
+	}
+	if got := entries.FilterMessageSnippet("order").FilterField("order_id", 42); len(got) != 1 {
+		t.Errorf("records with order_id = %d, want 1", len(got))
+	}
+
+	tl.Reset()
+	if got := tl.Entries(); len(got) != 0 {
+		t.Errorf("entries after Reset = %d, want 0", len(got))
+	}
+}
+
+func TestAssertMasked(t *testing.T) {
+	tl := logger.NewTestLogger(logger.Option{
+		IsEnable:            true,
+		EnableMaskingFields: true,
+		MaskingFields:       []string{"password"},
+	})
+
+	tl.Info(context.Background(), "login", logger.Field{Key: "customer", Val: map[string]interface{}{
+		"email":    "jane@example.com",
+		"password": "plain-password",
+	}})
+
+	entry := tl.Entries()[0]
+	logtest.AssertMasked(t, entry, "customer.password")
+	logtest.AssertNotLogged(t, tl.Entries(), "plain-password")
+
+	r := &recorder{TB: t}
+	logtest.AssertMasked(r, entry, "customer.email")
+	logtest.AssertMasked(r, entry, "customer.phone")
+	logtest.AssertNotLogged(r, tl.Entries(), "jane@example.com")
+	if len(r.failures) != 3 {
+		t.Errorf("failures = %q, want 3", r.failures)
+	}
+}
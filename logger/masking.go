@@ -0,0 +1,628 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maskedValue replaces values masked with MaskFull.
+const maskedValue = "******"
+
+// MaskStrategy decides how a masked value is rendered.
+type MaskStrategy string
+
+const (
+	// MaskFull replaces the whole value: ******
+	MaskFull MaskStrategy = "full"
+	// MaskLast4 keeps the last 4 characters: ************1234
+	MaskLast4 MaskStrategy = "last4"
+	// MaskEmail masks the local part of an email: ******@example.com
+	MaskEmail MaskStrategy = "email"
+	// MaskHash replaces the value by a salted hash, so it stays correlatable: sha256:5e884898da280471
+	MaskHash MaskStrategy = "hash"
+)
+
+// MaskingRule masks the values of keys matched by name, pattern or JSON path.
+type MaskingRule struct {
+	// Key matches the key name exactly.
+	Key string
+	// Pattern matches the key name with a regular expression.
+	Pattern string
+	// Path matches the key by its JSON path from the logged value, e.g.
+	// $.customer.card.number. * matches any key and [*] any slice element.
+	Path string
+	// Strategy decides how the value is masked. Default: MaskFull.
+	Strategy MaskStrategy
+}
+
+// MaskingDetector detects sensitive values regardless of their key, even
+// inside free text.
+type MaskingDetector string
+
+const (
+	// DetectCreditCard masks card numbers of the major networks passing the
+	// Luhn check, keeping the last 4 digits. Detection is heuristic: order or
+	// account numbers that happen to look like a card number are masked too.
+	DetectCreditCard MaskingDetector = "credit_card"
+	// DetectJWT masks JSON Web Tokens.
+	DetectJWT MaskingDetector = "jwt"
+	// DetectBearerToken masks the token of "Bearer <token>" credentials.
+	DetectBearerToken MaskingDetector = "bearer_token"
+)
+
+var (
+	creditCardRegexp  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	jwtRegexp         = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bearerTokenRegexp = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+)
+
+const (
+	// logTag is the struct tag controlling how a field is logged.
+	logTag     = "log"
+	logTagOmit = "omit"
+	logTagMask = "mask"
+
+	// pathWildcard matches any key in a path rule, pathElement any slice element.
+	pathWildcard = "*"
+	pathElement  = "[*]"
+
+	// maxMaskDepth bounds how deep nested data is walked.
+	maxMaskDepth = 64
+)
+
+// pathRule is a MaskingRule matching keys by their JSON path.
+type pathRule struct {
+	segments []string
+	strategy MaskStrategy
+}
+
+// newPathRule parses a JSON path like $.customer.cards[*].number.
+func newPathRule(path string, strategy MaskStrategy) pathRule {
+	rule := pathRule{strategy: strategy}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	for _, segment := range strings.Split(path, ".") {
+		// Element index is not tracked, any index matches every element
+		for {
+			open := strings.Index(segment, "[")
+			if open < 0 {
+				break
+			}
+
+			if open > 0 {
+				rule.segments = append(rule.segments, segment[:open])
+			}
+
+			rule.segments = append(rule.segments, pathElement)
+
+			end := strings.Index(segment, "]")
+			if end < open {
+				end = open
+			}
+
+			segment = segment[end+1:]
+		}
+
+		if segment != "" {
+			rule.segments = append(rule.segments, segment)
+		}
+	}
+
+	return rule
+}
+
+func (r pathRule) match(path []string) bool {
+	if len(path) != len(r.segments) {
+		return false
+	}
+
+	for i, segment := range r.segments {
+		if segment == path[i] || (segment == pathWildcard && path[i] != pathElement) {
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// appendPath returns a new path with key added, leaving path untouched.
+func appendPath(path []string, key string) []string {
+	next := make([]string, len(path), len(path)+1)
+	copy(next, path)
+
+	return append(next, key)
+}
+
+// jsonFieldName returns the key encoding/json uses for field.
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+// tagStrategy parses the mask strategy of a log struct tag: mask or mask:<strategy>.
+func tagStrategy(tag string) (MaskStrategy, bool) {
+	if tag == logTagMask {
+		return MaskFull, true
+	}
+
+	if strategy, ok := strings.CutPrefix(tag, logTagMask+":"); ok {
+		return MaskStrategy(strategy), true
+	}
+
+	return "", false
+}
+
+// isJSONMarshaler reports whether val encodes itself, like time.Time.
+func isJSONMarshaler(val reflect.Value) bool {
+	if val.Type().Implements(jsonMarshalerType) || val.Type().Implements(textMarshalerType) {
+		return true
+	}
+
+	ptr := reflect.PointerTo(val.Type())
+	return ptr.Implements(jsonMarshalerType) || ptr.Implements(textMarshalerType)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// patternRule is a MaskingRule matching keys by regular expression.
+type patternRule struct {
+	pattern  *regexp.Regexp
+	strategy MaskStrategy
+}
+
+// masker masks sensitive values before they are logged.
+type masker struct {
+	enable          bool
+	caseInsensitive bool
+	keys            map[string]MaskStrategy
+	patterns        []patternRule
+	paths           []pathRule
+	detectors       []MaskingDetector
+	hashSalt        string
+	protoMarshal    protojson.MarshalOptions
+}
+
+func newMasker(opt Option) *masker {
+	m := &masker{
+		enable:          opt.EnableMaskingFields,
+		caseInsensitive: opt.MaskingCaseInsensitive,
+		keys:            make(map[string]MaskStrategy),
+		detectors:       opt.MaskingDetectors,
+		hashSalt:        opt.MaskingHashSalt,
+		protoMarshal:    newProtoMarshal(opt.Proto),
+	}
+
+	for _, fieldName := range opt.MaskingFields {
+		m.keys[m.normalizeKey(fieldName)] = MaskFull
+	}
+
+	for _, rule := range opt.MaskingRules {
+		strategy := rule.Strategy
+		if strategy == "" {
+			strategy = MaskFull
+		}
+
+		if rule.Key != "" {
+			m.keys[m.normalizeKey(rule.Key)] = strategy
+		}
+
+		if rule.Path != "" {
+			m.paths = append(m.paths, newPathRule(rule.Path, strategy))
+		}
+
+		if rule.Pattern != "" {
+			pattern := rule.Pattern
+			if m.caseInsensitive {
+				pattern = "(?i)" + pattern
+			}
+
+			m.patterns = append(m.patterns, patternRule{
+				pattern:  regexp.MustCompile(pattern),
+				strategy: strategy,
+			})
+		}
+	}
+
+	return m
+}
+
+func (m *masker) normalizeKey(key string) string {
+	if m.caseInsensitive {
+		return strings.ToLower(key)
+	}
+
+	return key
+}
+
+// strategyFor returns the strategy of the first rule matching the last key of
+// path. Path rules are checked first as they are the most specific.
+func (m *masker) strategyFor(path []string) (MaskStrategy, bool) {
+	for _, rule := range m.paths {
+		if rule.match(path) {
+			return rule.strategy, true
+		}
+	}
+
+	return m.strategyForKey(path[len(path)-1])
+}
+
+// strategyForKey returns the strategy of the first key or pattern rule
+// matching key.
+func (m *masker) strategyForKey(key string) (MaskStrategy, bool) {
+	if strategy, ok := m.keys[m.normalizeKey(key)]; ok {
+		return strategy, true
+	}
+
+	for _, rule := range m.patterns {
+		if rule.pattern.MatchString(key) {
+			return rule.strategy, true
+		}
+	}
+
+	return "", false
+}
+
+// mask masks the values of sensitive keys and the sensitive values found by
+// the detectors.
+func (m *masker) mask(input interface{}) interface{} {
+	if !m.enable {
+		return input
+	}
+
+	return m.maskAt(nil, input)
+}
+
+// maskField masks the value of a named field. Key and pattern rules apply to
+// the name of the field, path rules are rooted at its value.
+func (m *masker) maskField(key string, input interface{}) interface{} {
+	if !m.enable {
+		return input
+	}
+
+	if strategy, ok := m.strategyForKey(key); ok {
+		return m.maskValue(input, strategy)
+	}
+
+	return m.maskAt(nil, input)
+}
+
+// maskAt returns a masked copy of input found at path, input itself is never
+// modified.
+func (m *masker) maskAt(path []string, input interface{}) interface{} {
+	if str, ok := input.(string); ok {
+		return m.maskString(str)
+	}
+
+	// Guard against cyclic data
+	if len(path) > maxMaskDepth {
+		return "<max depth exceeded>"
+	}
+
+	if msg, ok := input.(proto.Message); ok {
+		data, err := m.protoToData(msg)
+		if err != nil {
+			return input
+		}
+
+		return m.maskAt(path, data)
+	}
+
+	val := reflect.ValueOf(input)
+
+	if val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return input
+		}
+
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Map:
+		// If it's a map, copy it with the sensitive keys masked
+		data := make(map[string]interface{}, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			keyStr := fmt.Sprint(iter.Key().Interface())
+			data[keyStr] = m.maskKey(appendPath(path, keyStr), iter.Value().Interface())
+		}
+
+		return data
+	case reflect.Slice, reflect.Array:
+		// Bytes are encoded as base64, there is nothing to mask inside
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			return input
+		}
+
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return input
+		}
+
+		elemPath := appendPath(path, pathElement)
+		data := make([]interface{}, val.Len())
+		for i := range data {
+			data[i] = m.maskAt(elemPath, val.Index(i).Interface())
+		}
+
+		return data
+	case reflect.Struct:
+		// Types with their own JSON encoding are masked as they encode
+		if isJSONMarshaler(val) {
+			return m.maskMarshaler(path, input)
+		}
+
+		return m.maskStruct(path, val)
+	}
+
+	return input
+}
+
+// maskMarshaler masks the JSON encoding of a type encoding itself. Types
+// encoded as a single value, like time.Time, are logged as they are.
+func (m *masker) maskMarshaler(path []string, input interface{}) interface{} {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return input
+	}
+
+	var data interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return input
+	}
+
+	switch data.(type) {
+	case map[string]interface{}, []interface{}:
+		return m.maskAt(path, data)
+	}
+
+	return input
+}
+
+// maskKey masks the value of the key at the end of path.
+func (m *masker) maskKey(path []string, input interface{}) interface{} {
+	if strategy, ok := m.strategyFor(path); ok {
+		return m.maskValue(input, strategy)
+	}
+
+	return m.maskAt(path, input)
+}
+
+// maskStruct converts a struct to a map keyed like encoding/json would,
+// honoring the log struct tag:
+//
+//	Password string `json:"password" log:"mask"`
+//	Card     string `json:"card" log:"mask:last4"`
+//	Internal string `json:"internal" log:"omit"`
+func (m *masker) maskStruct(path []string, val reflect.Value) map[string]interface{} {
+	data := make(map[string]interface{})
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldVal := val.Field(i)
+
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		tag := field.Tag.Get(logTag)
+		if tag == logTagOmit || (omitEmpty && fieldVal.IsZero()) {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened, like encoding/json does
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			if fieldVal.Kind() == reflect.Ptr {
+				if fieldVal.IsNil() {
+					continue
+				}
+
+				fieldVal = fieldVal.Elem()
+			}
+
+			if fieldVal.Kind() == reflect.Struct {
+				for key, value := range m.maskStruct(path, fieldVal) {
+					if _, ok := data[key]; !ok {
+						data[key] = value
+					}
+				}
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if strategy, ok := tagStrategy(tag); ok {
+			data[name] = m.maskValue(fieldVal.Interface(), strategy)
+			continue
+		}
+
+		data[name] = m.maskKey(appendPath(path, name), fieldVal.Interface())
+	}
+
+	return data
+}
+
+// maskString masks the sensitive values found by the detectors in free text.
+func (m *masker) maskString(str string) string {
+	if !m.enable {
+		return str
+	}
+
+	for _, detector := range m.detectors {
+		switch detector {
+		case DetectCreditCard:
+			str = creditCardRegexp.ReplaceAllStringFunc(str, func(match string) string {
+				digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+				if !cardIINValid(digits) || !luhnValid(digits) {
+					return match
+				}
+
+				return maskLast4(digits)
+			})
+		case DetectJWT:
+			str = jwtRegexp.ReplaceAllString(str, maskedValue)
+		case DetectBearerToken:
+			str = bearerTokenRegexp.ReplaceAllString(str, "${1}"+maskedValue)
+		}
+	}
+
+	return str
+}
+
+// maskValue renders a value of a sensitive key with the given strategy.
+func (m *masker) maskValue(val interface{}, strategy MaskStrategy) interface{} {
+	str, ok := scalarString(val)
+	if !ok {
+		// Nested data can only be masked as a whole
+		return maskedValue
+	}
+
+	switch strategy {
+	case MaskLast4:
+		return maskLast4(str)
+	case MaskEmail:
+		at := strings.LastIndex(str, "@")
+		if at <= 0 {
+			return maskedValue
+		}
+
+		return maskedValue + str[at:]
+	case MaskHash:
+		sum := sha256.Sum256([]byte(m.hashSalt + str))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	default:
+		return maskedValue
+	}
+}
+
+// scalarString formats scalar values, JSON numbers are kept in full precision.
+func scalarString(val interface{}) (string, bool) {
+	switch v := val.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, bool:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// maskLast4 masks everything but the last 4 characters.
+func maskLast4(str string) string {
+	if len(str) <= 4 {
+		return maskedValue
+	}
+
+	return strings.Repeat("*", len(str)-4) + str[len(str)-4:]
+}
+
+// cardIIN is a range of issuer identification numbers of a card network,
+// along with the lengths of its card numbers.
+type cardIIN struct {
+	low, high      int
+	minLen, maxLen int
+}
+
+// cardIINs are the networks recognized by DetectCreditCard. A random run of
+// digits passes the Luhn check one time in ten, requiring a known prefix and
+// length keeps most ids and amounts from being masked.
+var cardIINs = []cardIIN{
+	{low: 4, high: 4, minLen: 13, maxLen: 19},       // Visa
+	{low: 51, high: 55, minLen: 16, maxLen: 16},     // Mastercard
+	{low: 2221, high: 2720, minLen: 16, maxLen: 16}, // Mastercard 2-series
+	{low: 34, high: 34, minLen: 15, maxLen: 15},     // American Express
+	{low: 37, high: 37, minLen: 15, maxLen: 15},     // American Express
+	{low: 6011, high: 6011, minLen: 16, maxLen: 19}, // Discover
+	{low: 644, high: 649, minLen: 16, maxLen: 19},   // Discover
+	{low: 65, high: 65, minLen: 16, maxLen: 19},     // Discover
+	{low: 3528, high: 3589, minLen: 16, maxLen: 19}, // JCB
+	{low: 300, high: 305, minLen: 14, maxLen: 19},   // Diners Club
+	{low: 36, high: 36, minLen: 14, maxLen: 19},     // Diners Club
+	{low: 38, high: 39, minLen: 16, maxLen: 19},     // Diners Club
+	{low: 62, high: 62, minLen: 16, maxLen: 19},     // UnionPay
+	{low: 50, high: 50, minLen: 13, maxLen: 19},     // Maestro
+	{low: 56, high: 58, minLen: 13, maxLen: 19},     // Maestro
+}
+
+// cardIINValid reports whether digits starts with the prefix of a known card
+// network and has the length of its card numbers.
+func cardIINValid(digits string) bool {
+	for _, iin := range cardIINs {
+		if len(digits) < iin.minLen || len(digits) > iin.maxLen {
+			continue
+		}
+
+		width := len(strconv.Itoa(iin.high))
+		prefix, err := strconv.Atoi(digits[:width])
+		if err == nil && prefix >= iin.low && prefix <= iin.high {
+			return true
+		}
+	}
+
+	return false
+}
+
+// luhnValid reports whether digits passes the Luhn checksum.
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
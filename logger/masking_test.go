@@ -0,0 +1,359 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"github.com/insaneadinesia/gobang/logger/logtest"
+)
+
+// logged logs val under the data key and returns the value at path of the
+// record.
+func logged(t *testing.T, opt logger.Option, val interface{}, path string) interface{} {
+	t.Helper()
+
+	opt.IsEnable = true
+	opt.EnableMaskingFields = true
+	tl := logger.NewTestLogger(opt)
+
+	tl.Info(context.Background(), "masked", logger.Field{Key: "data", Val: val})
+
+	entries := tl.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("records = %d, want 1", len(entries))
+	}
+
+	got, ok := entries[0].Lookup(path)
+	if !ok {
+		t.Fatalf("%q is not logged: %v", path, entries[0].Fields)
+	}
+
+	return got
+}
+
+func TestMaskingRules(t *testing.T) {
+	opt := logger.Option{
+		MaskingFields:          []string{"password"},
+		MaskingCaseInsensitive: true,
+		MaskingRules: []logger.MaskingRule{
+			{Key: "card_number", Strategy: logger.MaskLast4},
+			{Key: "email", Strategy: logger.MaskEmail},
+			{Key: "user_id", Strategy: logger.MaskHash},
+			{Pattern: "_token$"},
+		},
+		MaskingHashSalt: "salt",
+	}
+
+	data := map[string]interface{}{
+		"Password":      "plain-password",
+		"card_number":   "4111111111111111",
+		"email":         "jane@example.com",
+		"user_id":       "u-42",
+		"refresh_token": "r-secret",
+		"name":          "Jane",
+	}
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{path: "data.Password", want: "******"},
+		{path: "data.card_number", want: "************1111"},
+		{path: "data.email", want: "******@example.com"},
+		{path: "data.refresh_token", want: "******"},
+		{path: "data.name", want: "Jane"},
+	}
+
+	for _, tt := range tests {
+		if got := logged(t, opt, data, tt.path); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	// Hashes are stable, so masked values stay correlatable
+	first := logged(t, opt, data, "data.user_id")
+	if second := logged(t, opt, data, "data.user_id"); first != second {
+		t.Errorf("user_id hashes differ: %v and %v", first, second)
+	}
+
+	opt.IsEnable = true
+	opt.EnableMaskingFields = true
+	tl := logger.NewTestLogger(opt)
+	tl.Info(context.Background(), "masked", logger.Field{Key: "data", Val: data})
+	logtest.AssertMasked(t, tl.Entries()[0], "data.user_id")
+	logtest.AssertNotLogged(t, tl.Entries(), "r-secret")
+}
+
+func TestMaskingDetectors(t *testing.T) {
+	opt := logger.Option{MaskingDetectors: []logger.MaskingDetector{
+		logger.DetectCreditCard,
+		logger.DetectJWT,
+		logger.DetectBearerToken,
+	}}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "visa", text: "paid with 4111111111111111", want: "paid with ************1111"},
+		{name: "separated", text: "card 5555 5555 5555 4444 declined", want: "card ************4444 declined"},
+		{name: "amex", text: "amex 378282246310005", want: "amex ***********0005"},
+		{name: "luhn failure", text: "card 4111111111111112", want: "card 4111111111111112"},
+		{name: "unknown prefix", text: "order 1234567812345670", want: "order 1234567812345670"},
+		{name: "wrong length", text: "ref 3782822463100052", want: "ref 3782822463100052"},
+		{
+			name: "jwt",
+			text: "token eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig",
+			want: "token ******",
+		},
+		{name: "bearer", text: "Authorization: Bearer abc.def-123", want: "Authorization: Bearer ******"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logged(t, opt, tt.text, "data"); got != tt.want {
+				t.Errorf("masked %q = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+type card struct {
+	Number string `json:"number" log:"mask:last4"`
+	CVV    string `json:"cvv" log:"mask"`
+	Issuer string `json:"issuer"`
+	Secret string `json:"secret" log:"omit"`
+}
+
+type customer struct {
+	Name  string `json:"name"`
+	Card  card   `json:"card"`
+	Cards []card `json:"cards"`
+}
+
+func TestMaskingStructTags(t *testing.T) {
+	c := customer{
+		Name:  "Jane",
+		Card:  card{Number: "4111111111111111", CVV: "123", Issuer: "bank", Secret: "s3cret"},
+		Cards: []card{{Number: "5555555555554444", CVV: "456"}},
+	}
+
+	tl := logger.NewTestLogger(logger.Option{IsEnable: true, EnableMaskingFields: true})
+	tl.Info(context.Background(), "customer", logger.Field{Key: "customer", Val: c})
+	entry := tl.Entries()[0]
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{path: "customer.name", want: "Jane"},
+		{path: "customer.card.number", want: "************1111"},
+		{path: "customer.card.cvv", want: "******"},
+		{path: "customer.card.issuer", want: "bank"},
+	}
+
+	for _, tt := range tests {
+		if got, _ := entry.Lookup(tt.path); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	if _, ok := entry.Lookup("customer.card.secret"); ok {
+		t.Errorf("omitted field is logged")
+	}
+	logtest.AssertNotLogged(t, tl.Entries(), "s3cret")
+	logtest.AssertNotLogged(t, tl.Entries(), "5555555555554444")
+}
+
+func TestMaskingPaths(t *testing.T) {
+	opt := logger.Option{MaskingRules: []logger.MaskingRule{
+		{Path: "$.customer.card.number", Strategy: logger.MaskLast4},
+		{Path: "$.items[*].price"},
+		{Path: "$.*.token"},
+	}}
+
+	data := map[string]interface{}{
+		"customer": map[string]interface{}{
+			"card":   map[string]interface{}{"number": "4111111111111111"},
+			"number": "kept",
+		},
+		"items":   []interface{}{map[string]interface{}{"price": 10}, map[string]interface{}{"price": 20}},
+		"session": map[string]interface{}{"token": "t-secret"},
+	}
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{path: "data.customer.card.number", want: "************1111"},
+		{path: "data.customer.number", want: "kept"},
+		{path: "data.session.token", want: "******"},
+	}
+
+	for _, tt := range tests {
+		if got := logged(t, opt, data, tt.path); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	items, _ := logged(t, opt, data, "data.items").([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("items = %v, want 2 items", items)
+	}
+	for i, item := range items {
+		if price := item.(map[string]interface{})["price"]; price != "******" {
+			t.Errorf("items[%d].price = %v, want masked", i, price)
+		}
+	}
+}
+
+type recipient struct {
+	AccountNo string `json:"account_no" log:"mask:last4"`
+	BankCode  string `json:"bank_code"`
+}
+
+type transfer struct {
+	Username   string                 `json:"username"`
+	Password   string                 `json:"password"`
+	Amount     int                    `json:"amount"`
+	Recipients []recipient            `json:"recipients"`
+	Metadata   map[string]interface{} `json:"metadata"`
+}
+
+func newTransferMap() map[string]interface{} {
+	return map[string]interface{}{
+		"username": "mamatosai",
+		"password": "1234567",
+		"amount":   100000,
+		"recipients": []interface{}{
+			map[string]interface{}{"account_no": "1234567890", "bank_code": "014"},
+			map[string]interface{}{"account_no": "0987654321", "bank_code": "008"},
+		},
+	}
+}
+
+func newTransfer() *transfer {
+	return &transfer{
+		Username: "mamatosai",
+		Password: "1234567",
+		Amount:   100000,
+		Recipients: []recipient{
+			{AccountNo: "1234567890", BankCode: "014"},
+			{AccountNo: "0987654321", BankCode: "008"},
+		},
+		Metadata: map[string]interface{}{"password": "1234567"},
+	}
+}
+
+func TestMaskingKeepsCallerData(t *testing.T) {
+	tl := logger.NewTestLogger(logger.Option{
+		IsEnable:            true,
+		EnableMaskingFields: true,
+		MaskingFields:       []string{"password", "account_no"},
+	})
+
+	reqMap := newTransferMap()
+	reqSlice := []interface{}{newTransferMap(), newTransferMap()}
+	reqStruct := newTransfer()
+
+	ctx := context.Background()
+	tl.Info(ctx, "map", reqMap)
+	tl.Info(ctx, "slice", reqSlice)
+	tl.Info(ctx, "struct", reqStruct)
+
+	if want := newTransferMap(); !reflect.DeepEqual(reqMap, want) {
+		t.Errorf("map is modified by logging: %v", reqMap)
+	}
+	if want := []interface{}{newTransferMap(), newTransferMap()}; !reflect.DeepEqual(reqSlice, want) {
+		t.Errorf("slice is modified by logging: %v", reqSlice)
+	}
+	if want := newTransfer(); !reflect.DeepEqual(reqStruct, want) {
+		t.Errorf("struct is modified by logging: %+v", reqStruct)
+	}
+
+	logtest.AssertNotLogged(t, tl.Entries(), "1234567890")
+	logtest.AssertMasked(t, tl.Entries().FilterMessage("struct")[0], "message_0.metadata.password")
+}
+
+func benchmarkMask(b *testing.B, body interface{}) {
+	ctx := context.Background()
+
+	for _, enable := range []bool{false, true} {
+		name := "disabled"
+		if enable {
+			name = "enabled"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			// Output is disabled, only formatting and masking are measured
+			log := logger.NewLogger(logger.Option{
+				EnableMaskingFields: enable,
+				MaskingFields:       []string{"password", "account_no"},
+			})
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				log.Info(ctx, "Request Body", body)
+			}
+		})
+	}
+}
+
+func BenchmarkMaskMap(b *testing.B) {
+	benchmarkMask(b, newTransferMap())
+}
+
+func BenchmarkMaskStruct(b *testing.B) {
+	benchmarkMask(b, newTransfer())
+}
+
+// Creds encodes itself, its fields must still be masked.
+type Creds struct {
+	User     string
+	Password string
+}
+
+func (c Creds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"user": c.User, "password": c.Password})
+}
+
+// Token encodes itself as text.
+type Token struct{ id string }
+
+func (t *Token) MarshalText() ([]byte, error) {
+	return []byte("tok-" + t.id), nil
+}
+
+func TestMaskingJSONMarshalers(t *testing.T) {
+	opt := logger.Option{MaskingFields: []string{"password"}}
+	creds := Creds{User: "alice", Password: "hunter2"}
+	at := time.Date(2025, 2, 26, 11, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		val  interface{}
+		path string
+		want interface{}
+	}{
+		{name: "value", val: creds, path: "data.password", want: "******"},
+		{name: "pointer", val: &creds, path: "data.password", want: "******"},
+		{name: "kept field", val: &creds, path: "data.user", want: "alice"},
+		{name: "nested", val: map[string]interface{}{"creds": creds}, path: "data.creds.password", want: "******"},
+		{name: "slice", val: []Creds{creds}, path: "data", want: []interface{}{map[string]interface{}{"user": "alice", "password": "******"}}},
+		{name: "time", val: at, path: "data", want: "2025-02-26T11:30:00Z"},
+		{name: "text", val: &Token{id: "42"}, path: "data", want: "tok-42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logged(t, opt, tt.val, tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,199 @@
 package logger
 
+import "time"
+
 type Option struct {
 	IsEnable            bool
 	EnableStackTrace    bool
 	EnableMaskingFields bool
 	MaskingFields       []string
+
+	// EnableCaller adds the caller file, line and function to every record.
+	EnableCaller bool
+	// StackTraceLevel is the minimum level recording a stack trace when
+	// EnableStackTrace is set. Default: warn, error in production mode.
+	StackTraceLevel string
+	// StackTraceFormat is the encoding of stack traces. Default: StackTraceString.
+	StackTraceFormat StackTraceFormat
+	// Production disables the development mode, in which DPanic level panics.
+	Production bool
+
+	// MaskingRules masks keys matched by name or pattern with a strategy.
+	MaskingRules []MaskingRule
+	// MaskingCaseInsensitive matches MaskingFields and MaskingRules ignoring case.
+	MaskingCaseInsensitive bool
+	// MaskingDetectors masks sensitive values wherever they appear, even inside free text.
+	MaskingDetectors []MaskingDetector
+	// MaskingHashSalt is mixed into the values masked with MaskHash.
+	MaskingHashSalt string
+
+	// Encoding is the format records are written in, EncodingJSON or
+	// EncodingConsole. Default: the EncodingEnv environment variable, then JSON.
+	Encoding Encoding
+
+	// Schema maps the field names of records to a log backend, see SchemaOption.
+	Schema SchemaOption
+
+	// Proto configures how proto messages are encoded.
+	Proto ProtoOption
+
+	// File writes logs to a rotated file, in addition to stdout when IsEnable is set.
+	File FileOption
+
+	// Sinks describes several outputs, each with its own level, encoding,
+	// schema and masking. When set, it replaces stdout and File.
+	Sinks []SinkOption
+
+	// Async writes logs from a background goroutine, see AsyncOption.
+	Async AsyncOption
+
+	// Sampling limits repeated and excessive records, see SamplingOption.
+	Sampling SamplingOption
+
+	// Level is the minimum level written. Default: info.
+	Level string
+	// ComponentLevels overrides Level per component, see WithComponent.
+	ComponentLevels map[string]string
+
+	// EnableSpanEvents adds log records to the active span as span events,
+	// Error level and above also set the span status to Error.
+	EnableSpanEvents bool
+	// SpanEventLevel is the minimum level recorded as span event. Default: info.
+	SpanEventLevel string
+}
+
+// StackTraceFormat is the encoding of the stacktrace field.
+type StackTraceFormat string
+
+const (
+	// StackTraceString writes the stack trace as a single string.
+	StackTraceString StackTraceFormat = "string"
+	// StackTraceArray writes the stack trace as an array of
+	// {"function", "file", "line"} frames.
+	StackTraceArray StackTraceFormat = "array"
+)
+
+// SchemaOption configures the field names of records. Keys is applied after
+// Profile, so both can be combined.
+type SchemaOption struct {
+	Profile SchemaProfile
+	// Keys renames keys of the records, such as {"app_name": "service"}. The
+	// entry keys xtime, level, message, caller, function and stacktrace can be
+	// renamed as well.
+	Keys map[string]string
+	// GCPProjectID is the project of the trace written by SchemaGCP, as
+	// projects/<id>/traces/<trace.id>. Without it the bare trace ID is written.
+	GCPProjectID string
+}
+
+// SinkOption configures an output of the logger.
+type SinkOption struct {
+	// Output is where records are written: "stdout", "stderr", "file" for File,
+	// or a socket address such as "unix:///var/run/log.sock",
+	// "tcp://localhost:5170" or "udp://localhost:5170".
+	Output string
+	File   FileOption
+	// Level is the minimum level written to this sink. Default: debug, still
+	// gated by Option.Level which defaults to the lowest level of the sinks.
+	Level    string
+	Encoding Encoding
+	Schema   SchemaOption
+	// MaskingFields, MaskingRules and MaskingDetectors mask values in this sink
+	// only, in addition to the masking of the Option.
+	MaskingFields    []string
+	MaskingRules     []MaskingRule
+	MaskingDetectors []MaskingDetector
+}
+
+// FileOption configures file output. File output is enabled when Path is set.
+type FileOption struct {
+	Path string
+	// MaxSizeMB rotates the file when it reaches this size. Default: 100.
+	MaxSizeMB int
+	// RotateInterval rotates the file on a fixed interval regardless of its size.
+	RotateInterval time.Duration
+	// MaxAge removes rotated files older than this, rounded up to whole days.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept. Default: all.
+	MaxBackups int
+	// Compress gzips rotated files.
+	Compress bool
+	// ReopenOnSIGHUP reopens the file on SIGHUP, for logrotate compatibility.
+	ReopenOnSIGHUP bool
+}
+
+// AsyncOption configures the buffered asynchronous writer. Call Sync or Close
+// on the logger before exiting, so queued records are not lost.
+type AsyncOption struct {
+	Enable bool
+	// BufferSize is the number of records queued. Default: 1024.
+	BufferSize int
+	// FlushInterval is how often queued records are flushed to the output. Default: 1s.
+	FlushInterval time.Duration
+	// Policy decides what happens when the buffer is full. Default: OverflowBlock.
+	Policy OverflowPolicy
+}
+
+// OverflowPolicy decides what happens to a record written to a full buffer.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits until there is room in the buffer.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest drops the record being written.
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest drops the oldest queued record to make room.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+// SamplingOption configures sampling per message and level, and a global rate
+// limit. A summary of suppressed records is written periodically.
+type SamplingOption struct {
+	Enable bool
+	// Interval is the window the per message counts are reset on. Default: 1s.
+	Interval time.Duration
+	// First records of a message and level are written every interval. Default: 100.
+	First int
+	// Thereafter every Mth record is written after First, 0 drops the rest. Default: 100.
+	Thereafter int
+	// RateLimit caps the records written per second across all messages. Default: no limit.
+	RateLimit int
+	// SummaryInterval is how often the summary of suppressed records is written. Default: 1m.
+	SummaryInterval time.Duration
+}
+
+// ProtoOption configures the protojson encoding of logged proto messages.
+type ProtoOption struct {
+	// UseProtoNames logs fields by their proto name instead of their lowerCamelCase JSON name.
+	UseProtoNames bool
+	// EmitDefaults logs fields holding their default value.
+	EmitDefaults bool
+}
+
+// HTTPMiddlewareOption configures the middleware logging the TDR of every request.
+type HTTPMiddlewareOption struct {
+	ServiceName    string
+	ServiceVersion string
+	ServicePort    int
+	Tag            string
+	// MaxBodySize caps the request and response body logged. Default: 64KB.
+	MaxBodySize int
+	// SkipPaths are served without logging, e.g. health checks.
+	SkipPaths []string
+	// SensitiveHeaders are request headers logged masked, on top of
+	// Authorization, Proxy-Authorization, Cookie, X-Api-Key and X-Auth-Token.
+	SensitiveHeaders []string
+}
+
+// GRPCInterceptorOption configures the interceptors logging the TDR of every gRPC call.
+type GRPCInterceptorOption struct {
+	ServiceName    string
+	ServiceVersion string
+	ServicePort    int
+	Tag            string
+	// SkipMethods are served without logging, e.g. /grpc.health.v1.Health/Check.
+	SkipMethods []string
+	// SensitiveMetadata are incoming metadata keys logged masked, on top of
+	// authorization, proxy-authorization, cookie, x-api-key and x-auth-token.
+	SensitiveMetadata []string
 }
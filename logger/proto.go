@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"encoding/json"
+
+	"github.com/insaneadinesia/gobang/logger/logpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// protoToData converts a proto message to generic JSON data using protojson,
+// so proto JSON names, oneofs, enums and well-known types are honored. Fields
+// marked with the (gobang.log.sensitive) option are masked.
+func (m *masker) protoToData(msg proto.Message) (interface{}, error) {
+	b, err := m.protoMarshal.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	if m.enable {
+		m.maskSensitiveProto(msg.ProtoReflect(), data)
+	}
+
+	return data, nil
+}
+
+// maskSensitiveProto masks the sensitive fields of msg in data, its protojson
+// encoding. data is owned by the masker so it is modified in place.
+func (m *masker) maskSensitiveProto(msg protoreflect.Message, data interface{}) {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		// Well-known types like Timestamp are not encoded as object
+		return
+	}
+
+	// Any payload is inlined next to its @type
+	if any, ok := msg.Interface().(*anypb.Any); ok {
+		payload, err := any.UnmarshalNew()
+		if err != nil {
+			return
+		}
+
+		m.maskSensitiveProto(payload.ProtoReflect(), obj)
+		return
+	}
+
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+
+		key := field.JSONName()
+		if m.protoMarshal.UseProtoNames {
+			key = string(field.Name())
+		}
+
+		val, ok := obj[key]
+		if !ok {
+			continue
+		}
+
+		if isSensitiveField(field) {
+			obj[key] = m.maskValue(val, MaskFull)
+			continue
+		}
+
+		switch {
+		case field.IsList() && field.Message() != nil:
+			list := msg.Get(field).List()
+			items, _ := val.([]interface{})
+			for j := 0; j < list.Len() && j < len(items); j++ {
+				m.maskSensitiveProto(list.Get(j).Message(), items[j])
+			}
+		case field.IsMap() && field.MapValue().Message() != nil:
+			entries, _ := val.(map[string]interface{})
+			msg.Get(field).Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				m.maskSensitiveProto(v.Message(), entries[k.String()])
+				return true
+			})
+		case field.Message() != nil && !field.IsList() && !field.IsMap():
+			m.maskSensitiveProto(msg.Get(field).Message(), val)
+		}
+	}
+}
+
+// isSensitiveField reports whether the field is marked with (gobang.log.sensitive) = true.
+func isSensitiveField(field protoreflect.FieldDescriptor) bool {
+	opts, ok := field.Options().(*descriptorpb.FieldOptions)
+	if !ok || opts == nil {
+		return false
+	}
+
+	sensitive, _ := proto.GetExtension(opts, logpb.E_Sensitive).(bool)
+	return sensitive
+}
+
+// newProtoMarshal returns the protojson options used to log proto messages.
+func newProtoMarshal(opt ProtoOption) protojson.MarshalOptions {
+	return protojson.MarshalOptions{
+		UseProtoNames:   opt.UseProtoNames,
+		EmitUnpopulated: opt.EmitDefaults,
+	}
+}
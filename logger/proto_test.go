@@ -0,0 +1,131 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"github.com/insaneadinesia/gobang/logger/logpb"
+	"github.com/insaneadinesia/gobang/logger/logtest"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newPayment builds a payment message whose card numbers are marked with
+// (gobang.log.sensitive), without generated code.
+func newPayment(t *testing.T) proto.Message {
+	t.Helper()
+
+	sensitive := &descriptorpb.FieldOptions{}
+	proto.SetExtension(sensitive, logpb.E_Sensitive, true)
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, opts *descriptorpb.FieldOptions) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:    proto.String(name),
+			Number:  proto.Int32(number),
+			Label:   descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:    typ.Enum(),
+			Options: opts,
+		}
+	}
+
+	cards := field("cards", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, nil)
+	cards.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	cards.TypeName = proto.String(".test.Card")
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test/payment.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Card"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("card_number", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, sensitive),
+					field("holder_name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, nil),
+				},
+			},
+			{
+				Name: proto.String("Payment"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("payment_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, nil),
+					field("pin", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, sensitive),
+					cards,
+					field("amount", 4, descriptorpb.FieldDescriptorProto_TYPE_INT64, nil),
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("build descriptor: %v", err)
+	}
+
+	cardDesc := fd.Messages().ByName("Card")
+	newCard := func(number, holder string) protoreflect.Value {
+		card := dynamicpb.NewMessage(cardDesc)
+		card.Set(cardDesc.Fields().ByName("card_number"), protoreflect.ValueOfString(number))
+		card.Set(cardDesc.Fields().ByName("holder_name"), protoreflect.ValueOfString(holder))
+		return protoreflect.ValueOfMessage(card)
+	}
+
+	paymentDesc := fd.Messages().ByName("Payment")
+	payment := dynamicpb.NewMessage(paymentDesc)
+	payment.Set(paymentDesc.Fields().ByName("payment_id"), protoreflect.ValueOfString("pay-1"))
+	payment.Set(paymentDesc.Fields().ByName("pin"), protoreflect.ValueOfString("9876"))
+
+	list := payment.Mutable(paymentDesc.Fields().ByName("cards")).List()
+	list.Append(newCard("4111111111111111", "Jane"))
+	list.Append(newCard("5555555555554444", "John"))
+
+	return payment
+}
+
+func TestProtoSensitiveFields(t *testing.T) {
+	tl := logger.NewTestLogger(logger.Option{IsEnable: true, EnableMaskingFields: true})
+	tl.Info(context.Background(), "payment", logger.Field{Key: "payment", Val: newPayment(t)})
+	entry := tl.Entries()[0]
+
+	// protojson names by default
+	if got, _ := entry.Lookup("payment.paymentId"); got != "pay-1" {
+		t.Errorf("payment.paymentId = %v, want pay-1", got)
+	}
+	logtest.AssertMasked(t, entry, "payment.pin")
+
+	cards, _ := entry.Lookup("payment.cards")
+	items, _ := cards.([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("payment.cards = %v, want 2 cards", cards)
+	}
+	for i, item := range items {
+		card := item.(map[string]interface{})
+		if card["cardNumber"] != "******" {
+			t.Errorf("cards[%d].cardNumber = %v, want masked", i, card["cardNumber"])
+		}
+	}
+
+	logtest.AssertNotLogged(t, tl.Entries(), "4111111111111111")
+	logtest.AssertNotLogged(t, tl.Entries(), "9876")
+}
+
+func TestProtoOption(t *testing.T) {
+	tl := logger.NewTestLogger(logger.Option{
+		IsEnable:            true,
+		EnableMaskingFields: true,
+		Proto:               logger.ProtoOption{UseProtoNames: true, EmitDefaults: true},
+	})
+	tl.Info(context.Background(), "payment", logger.Field{Key: "payment", Val: newPayment(t)})
+	entry := tl.Entries()[0]
+
+	if got, _ := entry.Lookup("payment.payment_id"); got != "pay-1" {
+		t.Errorf("payment.payment_id = %v, want pay-1", got)
+	}
+	logtest.AssertMasked(t, entry, "payment.pin")
+
+	// int64 is encoded as a string by protojson
+	if got, ok := entry.Lookup("payment.amount"); !ok || got != "0" {
+		t.Errorf("payment.amount = %v, want the default value", got)
+	}
+}
@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultSamplingInterval   = time.Second
+	defaultSamplingFirst      = 100
+	defaultSamplingThereafter = 100
+	defaultSummaryInterval    = time.Minute
+)
+
+// suppressionCounter counts the records dropped by sampling and rate limiting
+// since the last summary.
+type suppressionCounter struct {
+	sampled     atomic.Uint64
+	rateLimited atomic.Uint64
+}
+
+// newSamplingCore wraps core with per message sampling and a global rate limit.
+// TDR records are only rate limited, sampling them per message would drop
+// requests regardless of their route. Suppressed records are reported by a
+// summary line written straight to core.
+func newSamplingCore(core zapcore.Core, opt SamplingOption) (zapcore.Core, io.Closer) {
+	interval := opt.Interval
+	if interval <= 0 {
+		interval = defaultSamplingInterval
+	}
+
+	first, thereafter := opt.First, opt.Thereafter
+	if first <= 0 && thereafter <= 0 {
+		first, thereafter = defaultSamplingFirst, defaultSamplingThereafter
+	}
+
+	summaryInterval := opt.SummaryInterval
+	if summaryInterval <= 0 {
+		summaryInterval = defaultSummaryInterval
+	}
+
+	counter := &suppressionCounter{}
+
+	// Rate limit is applied after sampling, so sampled out records do not use it up
+	limited := core
+	if opt.RateLimit > 0 {
+		limited = &rateLimitCore{
+			Core:    core,
+			limiter: &rateLimiter{limit: opt.RateLimit},
+			counter: counter,
+		}
+	}
+
+	sampled := zapcore.NewSamplerWithOptions(limited, interval, first, thereafter,
+		zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped != 0 {
+				counter.sampled.Add(1)
+			}
+		}),
+	)
+
+	// Every TDR has the same message, they bypass the sampler
+	sampled = &tdrBypassCore{Core: sampled, tdr: limited}
+
+	summary := &suppressionSummary{
+		core:     core,
+		counter:  counter,
+		interval: summaryInterval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go summary.loop()
+
+	return sampled, summary
+}
+
+// tdrBypassCore routes TDR records to tdr and the other records to Core.
+type tdrBypassCore struct {
+	zapcore.Core
+
+	tdr zapcore.Core
+}
+
+func (c *tdrBypassCore) With(fields []zapcore.Field) zapcore.Core {
+	return &tdrBypassCore{
+		Core: c.Core.With(fields),
+		tdr:  c.tdr.With(fields),
+	}
+}
+
+func (c *tdrBypassCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Message == tdrMessage {
+		return c.tdr.Check(ent, ce)
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// rateLimitCore drops records beyond the rate limit. DPanic and above are
+// always written.
+type rateLimitCore struct {
+	zapcore.Core
+
+	limiter *rateLimiter
+	counter *suppressionCounter
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{
+		Core:    c.Core.With(fields),
+		limiter: c.limiter,
+		counter: c.counter,
+	}
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	if ent.Level < zapcore.DPanicLevel && !c.limiter.Allow(ent.Time) {
+		c.counter.rateLimited.Add(1)
+		return ce
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// rateLimiter allows up to limit records per second.
+type rateLimiter struct {
+	limit int
+
+	mu     sync.Mutex
+	window int64
+	count  int
+}
+
+func (l *rateLimiter) Allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if window := now.Unix(); window != l.window {
+		l.window = window
+		l.count = 0
+	}
+
+	if l.count >= l.limit {
+		return false
+	}
+
+	l.count++
+	return true
+}
+
+// suppressionSummary periodically writes how many records were suppressed.
+type suppressionSummary struct {
+	core     zapcore.Core
+	counter  *suppressionCounter
+	interval time.Duration
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func (s *suppressionSummary) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.write()
+		case <-s.stop:
+			s.write()
+			return
+		}
+	}
+}
+
+func (s *suppressionSummary) write() {
+	sampled := s.counter.sampled.Swap(0)
+	rateLimited := s.counter.rateLimited.Swap(0)
+
+	if sampled == 0 && rateLimited == 0 {
+		return
+	}
+
+	ent := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Now(),
+		Message: "log records suppressed",
+	}
+
+	if ce := s.core.Check(ent, nil); ce != nil {
+		ce.Write(
+			zap.Uint64("suppressed_sampled", sampled),
+			zap.Uint64("suppressed_rate_limited", rateLimited),
+			zap.String("suppressed_interval", s.interval.String()),
+		)
+	}
+}
+
+// Close writes the last summary and stops the summary loop.
+func (s *suppressionSummary) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+
+	return nil
+}
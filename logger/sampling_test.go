@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newSampledLogger(t *testing.T, opt SamplingOption) (*zap.Logger, *observer.ObservedLogs, func() error) {
+	t.Helper()
+
+	observed, logs := observer.New(zapcore.DebugLevel)
+	core, summary := newSamplingCore(observed, opt)
+
+	// Records share a rate limit window whatever the time the test runs at
+	return zap.New(core, zap.WithClock(fixedClock{})), logs, summary.Close
+}
+
+type fixedClock struct{}
+
+func (fixedClock) Now() time.Time { return time.Unix(1740540000, 0) }
+
+func (fixedClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+func TestSamplingPerMessage(t *testing.T) {
+	zapLogger, logs, closeSummary := newSampledLogger(t, SamplingOption{
+		Interval:        time.Minute,
+		First:           2,
+		SummaryInterval: time.Hour,
+	})
+
+	for i := 0; i < 5; i++ {
+		zapLogger.Info("cache miss")
+		zapLogger.Info("cache hit")
+	}
+
+	if got := logs.FilterMessage("cache miss").Len(); got != 2 {
+		t.Errorf("cache miss records = %d, want 2", got)
+	}
+	if got := logs.FilterMessage("cache hit").Len(); got != 2 {
+		t.Errorf("cache hit records = %d, want 2", got)
+	}
+
+	if err := closeSummary(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := closeSummary(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	summary := logs.FilterMessage("log records suppressed").All()
+	if len(summary) != 1 {
+		t.Fatalf("summary records = %d, want 1", len(summary))
+	}
+	if got := summary[0].ContextMap()["suppressed_sampled"]; got != uint64(6) {
+		t.Errorf("suppressed_sampled = %v, want 6", got)
+	}
+}
+
+func TestSamplingKeepsTDR(t *testing.T) {
+	zapLogger, logs, closeSummary := newSampledLogger(t, SamplingOption{
+		Interval:        time.Minute,
+		First:           1,
+		SummaryInterval: time.Hour,
+	})
+	defer closeSummary()
+
+	for i := 0; i < 10; i++ {
+		zapLogger.Info(tdrMessage, zap.String("app_uri", fmt.Sprintf("/orders/%d", i)))
+	}
+
+	if got := logs.FilterMessage(tdrMessage).Len(); got != 10 {
+		t.Errorf("TDR records = %d, want 10", got)
+	}
+}
+
+func TestSamplingRateLimit(t *testing.T) {
+	zapLogger, logs, closeSummary := newSampledLogger(t, SamplingOption{
+		Interval:        time.Minute,
+		First:           100,
+		RateLimit:       3,
+		SummaryInterval: time.Hour,
+	})
+
+	for i := 0; i < 5; i++ {
+		zapLogger.Info(fmt.Sprintf("record %d", i))
+	}
+	zapLogger.Info(tdrMessage)
+
+	if got := logs.Len(); got != 3 {
+		t.Errorf("records = %d, want 3", got)
+	}
+
+	closeSummary()
+
+	summary := logs.FilterMessage("log records suppressed").All()
+	if len(summary) != 1 {
+		t.Fatalf("summary records = %d, want 1", len(summary))
+	}
+	if got := summary[0].ContextMap()["suppressed_rate_limited"]; got != uint64(3) {
+		t.Errorf("suppressed_rate_limited = %v, want 3", got)
+	}
+}
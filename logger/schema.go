@@ -0,0 +1,343 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SchemaProfile maps records to the field conventions of a log backend, so
+// they are parsed natively.
+type SchemaProfile string
+
+const (
+	// SchemaECS follows the Elastic Common Schema: @timestamp, log.level,
+	// service.name, http.*, url.original, event.duration and error.*.
+	SchemaECS SchemaProfile = "ecs"
+	// SchemaGCP follows Google Cloud Logging: severity, timestamp,
+	// logging.googleapis.com/trace, sourceLocation, serviceContext and
+	// httpRequest for TDR records.
+	SchemaGCP SchemaProfile = "gcp"
+	// SchemaDatadog follows the Datadog standard attributes: status, service,
+	// dd.trace_id, dd.span_id, http.*, duration and error.*.
+	SchemaDatadog SchemaProfile = "datadog"
+)
+
+// ecsVersion is the version of the Elastic Common Schema written by SchemaECS.
+const ecsVersion = "8.11.0"
+
+// schema is how a SchemaProfile changes the records.
+type schema struct {
+	// configure changes the keys and encoders of the entry
+	configure func(cfg *zapcore.EncoderConfig)
+	// rename maps the keys of the fields
+	rename map[string]string
+	// convert changes the fields whose value must be converted
+	convert func(c *schemaCore, fields []zapcore.Field) []zapcore.Field
+	// entry adds fields taken from the entry, such as the caller
+	entry func(ent zapcore.Entry) []zapcore.Field
+}
+
+var schemas = map[SchemaProfile]schema{
+	SchemaECS: {
+		configure: func(cfg *zapcore.EncoderConfig) {
+			cfg.TimeKey = "@timestamp"
+			cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+			cfg.LevelKey = "log.level"
+			cfg.NameKey = "log.logger"
+			cfg.StacktraceKey = "error.stack_trace"
+			cfg.CallerKey = zapcore.OmitKey
+			cfg.FunctionKey = zapcore.OmitKey
+		},
+		rename: map[string]string{
+			"app_name":          "service.name",
+			"app_version":       "service.version",
+			"app_port":          "service.port",
+			"app_tag":           "labels.app_tag",
+			"app_method":        "http.request.method",
+			"app_uri":           "url.original",
+			"app_response_code": "http.response.status_code",
+			"app_error":         "error.message",
+			"app_component":     "log.logger",
+		},
+		convert: func(c *schemaCore, fields []zapcore.Field) []zapcore.Field {
+			return convertDuration(fields, "event.duration")
+		},
+		entry: func(ent zapcore.Entry) []zapcore.Field {
+			fields := []zapcore.Field{zap.String("ecs.version", ecsVersion)}
+			if ent.Caller.Defined {
+				fields = append(fields,
+					zap.String("log.origin.file.name", strings.TrimSuffix(ent.Caller.TrimmedPath(), ":"+strconv.Itoa(ent.Caller.Line))),
+					zap.Int("log.origin.file.line", ent.Caller.Line),
+					zap.String("log.origin.function", ent.Caller.Function),
+				)
+			}
+
+			return fields
+		},
+	},
+	SchemaGCP: {
+		configure: func(cfg *zapcore.EncoderConfig) {
+			cfg.TimeKey = "timestamp"
+			cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+			cfg.LevelKey = "severity"
+			cfg.EncodeLevel = gcpLevelEncoder
+			cfg.StacktraceKey = "stack_trace"
+			cfg.CallerKey = zapcore.OmitKey
+			cfg.FunctionKey = zapcore.OmitKey
+		},
+		rename: map[string]string{
+			"span.id": "logging.googleapis.com/spanId",
+		},
+		convert: convertGCP,
+		entry: func(ent zapcore.Entry) []zapcore.Field {
+			if !ent.Caller.Defined {
+				return nil
+			}
+
+			return []zapcore.Field{zap.Any("logging.googleapis.com/sourceLocation", map[string]interface{}{
+				"file":     ent.Caller.File,
+				"line":     strconv.Itoa(ent.Caller.Line),
+				"function": ent.Caller.Function,
+			})}
+		},
+	},
+	SchemaDatadog: {
+		configure: func(cfg *zapcore.EncoderConfig) {
+			cfg.TimeKey = "timestamp"
+			cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+			cfg.LevelKey = "status"
+			cfg.NameKey = "logger.name"
+			cfg.StacktraceKey = "error.stack"
+			cfg.FunctionKey = "logger.method_name"
+		},
+		rename: map[string]string{
+			"app_name":          "service",
+			"app_version":       "version",
+			"app_method":        "http.method",
+			"app_uri":           "http.url",
+			"app_response_code": "http.status_code",
+			"app_error":         "error.message",
+			"app_component":     "logger.name",
+		},
+		convert: func(c *schemaCore, fields []zapcore.Field) []zapcore.Field {
+			for i, field := range fields {
+				switch field.Key {
+				case "trace.id":
+					fields[i] = datadogID("dd.trace_id", fieldString(field))
+				case "span.id":
+					fields[i] = datadogID("dd.span_id", fieldString(field))
+				}
+			}
+
+			return convertDuration(fields, "duration")
+		},
+	},
+}
+
+// applySchema changes the entry keys and encoders of cfg to those of opt.
+// Keys of opt.Keys matching an entry key rename it.
+func applySchema(cfg *zapcore.EncoderConfig, opt SchemaOption) {
+	if s, ok := schemas[opt.Profile]; ok {
+		s.configure(cfg)
+	}
+
+	for _, key := range []*string{
+		&cfg.TimeKey, &cfg.LevelKey, &cfg.NameKey, &cfg.CallerKey,
+		&cfg.FunctionKey, &cfg.MessageKey, &cfg.StacktraceKey,
+	} {
+		if renamed, ok := opt.Keys[*key]; ok {
+			*key = renamed
+		}
+	}
+}
+
+// schemaCore renames and converts the fields of records to a schema.
+type schemaCore struct {
+	zapcore.Core
+	schema    schema
+	keys      map[string]string
+	projectID string
+}
+
+// newSchemaCore wraps core with the schema of opt. The encoder of core must
+// be configured with applySchema.
+func newSchemaCore(core zapcore.Core, opt SchemaOption) zapcore.Core {
+	s, ok := schemas[opt.Profile]
+	if !ok && len(opt.Keys) == 0 {
+		return core
+	}
+
+	return &schemaCore{Core: core, schema: s, keys: opt.Keys, projectID: opt.GCPProjectID}
+}
+
+func (c *schemaCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(c.fields(fields))
+
+	return &clone
+}
+
+func (c *schemaCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *schemaCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.schema.entry != nil {
+		fields = append(c.schema.entry(ent), fields...)
+	}
+
+	return c.Core.Write(ent, c.fields(fields))
+}
+
+// fields returns a copy of fields renamed and converted to the schema.
+func (c *schemaCore) fields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	copy(out, fields)
+
+	if c.schema.convert != nil {
+		out = c.schema.convert(c, out)
+	}
+
+	for i := range out {
+		if renamed, ok := c.schema.rename[out[i].Key]; ok {
+			out[i].Key = renamed
+		}
+		if renamed, ok := c.keys[out[i].Key]; ok {
+			out[i].Key = renamed
+		}
+	}
+
+	return out
+}
+
+// fieldValue returns the value of field as it is encoded.
+func fieldValue(field zapcore.Field) interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+
+	return enc.Fields[field.Key]
+}
+
+func fieldString(field zapcore.Field) string {
+	str, _ := fieldValue(field).(string)
+	return str
+}
+
+// convertDuration replaces app_exec_time by key, in nanoseconds.
+func convertDuration(fields []zapcore.Field, key string) []zapcore.Field {
+	for i, field := range fields {
+		if field.Key != "app_exec_time" {
+			continue
+		}
+
+		if d, err := time.ParseDuration(fieldString(field)); err == nil {
+			fields[i] = zap.Int64(key, d.Nanoseconds())
+		}
+	}
+
+	return fields
+}
+
+// datadogID converts a hex trace or span ID to the decimal ID of its lower
+// 64 bits used by Datadog. Invalid IDs are skipped.
+func datadogID(key, hexID string) zapcore.Field {
+	if len(hexID) > 16 {
+		hexID = hexID[len(hexID)-16:]
+	}
+
+	id, err := strconv.ParseUint(hexID, 16, 64)
+	if err != nil || id == 0 {
+		return zap.Skip()
+	}
+
+	return zap.String(key, strconv.FormatUint(id, 10))
+}
+
+// convertGCP writes the trace of the project, groups app_name and
+// app_version into serviceContext, and the request of TDR records into
+// httpRequest.
+func convertGCP(c *schemaCore, fields []zapcore.Field) []zapcore.Field {
+	values := make(map[string]interface{})
+	out := fields[:0]
+	for _, field := range fields {
+		switch field.Key {
+		case "trace.id":
+			traceID := fieldString(field)
+			if strings.Trim(traceID, "0") == "" {
+				continue
+			}
+			if c.projectID != "" {
+				traceID = fmt.Sprintf("projects/%s/traces/%s", c.projectID, traceID)
+			}
+			out = append(out, zap.String("logging.googleapis.com/trace", traceID))
+		case "span.id":
+			if strings.Trim(fieldString(field), "0") != "" {
+				out = append(out, field)
+			}
+		case "app_name", "app_version", "app_method", "app_uri", "app_response_code", "app_exec_time":
+			values[field.Key] = fieldValue(field)
+		default:
+			out = append(out, field)
+		}
+	}
+
+	if _, ok := values["app_name"]; ok {
+		out = append(out, zap.Any("serviceContext", map[string]interface{}{
+			"service": values["app_name"],
+			"version": values["app_version"],
+		}))
+	}
+
+	// Only TDR records describe a whole request
+	if _, ok := values["app_response_code"]; ok {
+		request := map[string]interface{}{
+			"requestMethod": values["app_method"],
+			"requestUrl":    values["app_uri"],
+			"status":        values["app_response_code"],
+		}
+		if str, ok := values["app_exec_time"].(string); ok {
+			if d, err := time.ParseDuration(str); err == nil {
+				request["latency"] = fmt.Sprintf("%.9fs", d.Seconds())
+			}
+		}
+
+		return append(out, zap.Any("httpRequest", request))
+	}
+
+	for _, key := range []string{"app_method", "app_uri"} {
+		if val, ok := values[key]; ok {
+			out = append(out, zap.Any(key, val))
+		}
+	}
+
+	return out
+}
+
+// gcpSeverity maps levels to Cloud Logging severities.
+var gcpSeverity = map[zapcore.Level]string{
+	zapcore.DebugLevel:  "DEBUG",
+	zapcore.InfoLevel:   "INFO",
+	zapcore.WarnLevel:   "WARNING",
+	zapcore.ErrorLevel:  "ERROR",
+	zapcore.DPanicLevel: "CRITICAL",
+	zapcore.PanicLevel:  "ALERT",
+	zapcore.FatalLevel:  "EMERGENCY",
+}
+
+func gcpLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	severity, ok := gcpSeverity[level]
+	if !ok {
+		severity = "DEFAULT"
+	}
+
+	enc.AppendString(severity)
+}
@@ -0,0 +1,149 @@
+package logger_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// logSchemaTDR writes a TDR to a file sink with schema and returns the
+// decoded record.
+func logSchemaTDR(t *testing.T, schema logger.SchemaOption) map[string]interface{} {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := logger.NewLogger(logger.Option{
+		Sinks: []logger.SinkOption{{Output: "file", File: logger.FileOption{Path: path}, Schema: schema}},
+	})
+
+	traceID, _ := trace.TraceIDFromHex("b03b1bba60aa5e3e8c2ee0ce141b0ad8")
+	spanID, _ := trace.SpanIDFromHex("e4fa02ad9b3bde14")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	ctx = logger.InjectCtx(ctx, logger.Context{
+		ServiceName:    "orders",
+		ServiceVersion: "v1.0.0",
+		ReqMethod:      "POST",
+		ReqURI:         "/orders",
+		RespCode:       200,
+		RespTime:       "29ms",
+	})
+	l.TDR(ctx)
+
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open log file: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		t.Fatal("log file is empty")
+	}
+
+	record := make(map[string]interface{})
+	if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+		t.Fatalf("decode record: %v", err)
+	}
+
+	return record
+}
+
+func assertRecord(t *testing.T, record map[string]interface{}, want map[string]interface{}) {
+	t.Helper()
+
+	for key, val := range want {
+		if got, ok := record[key]; !ok || !jsonEqual(got, val) {
+			t.Errorf("%s = %v, want %v", key, got, val)
+		}
+	}
+}
+
+func jsonEqual(got, want interface{}) bool {
+	a, _ := json.Marshal(got)
+	b, _ := json.Marshal(want)
+
+	return string(a) == string(b)
+}
+
+func TestSchemaECS(t *testing.T) {
+	record := logSchemaTDR(t, logger.SchemaOption{Profile: logger.SchemaECS})
+
+	assertRecord(t, record, map[string]interface{}{
+		"log.level":                 "info",
+		"service.name":              "orders",
+		"service.version":           "v1.0.0",
+		"http.request.method":       "POST",
+		"url.original":              "/orders",
+		"http.response.status_code": 200,
+		"event.duration":            29000000,
+		"trace.id":                  "b03b1bba60aa5e3e8c2ee0ce141b0ad8",
+		"ecs.version":               "8.11.0",
+	})
+
+	if _, ok := record["@timestamp"]; !ok {
+		t.Errorf("@timestamp is missing: %v", record)
+	}
+}
+
+func TestSchemaGCP(t *testing.T) {
+	record := logSchemaTDR(t, logger.SchemaOption{Profile: logger.SchemaGCP, GCPProjectID: "my-project"})
+
+	assertRecord(t, record, map[string]interface{}{
+		"severity":                      "INFO",
+		"logging.googleapis.com/trace":  "projects/my-project/traces/b03b1bba60aa5e3e8c2ee0ce141b0ad8",
+		"logging.googleapis.com/spanId": "e4fa02ad9b3bde14",
+		"serviceContext":                map[string]interface{}{"service": "orders", "version": "v1.0.0"},
+		"httpRequest": map[string]interface{}{
+			"requestMethod": "POST",
+			"requestUrl":    "/orders",
+			"status":        200,
+			"latency":       "0.029000000s",
+		},
+	})
+}
+
+func TestSchemaDatadog(t *testing.T) {
+	record := logSchemaTDR(t, logger.SchemaOption{Profile: logger.SchemaDatadog})
+
+	assertRecord(t, record, map[string]interface{}{
+		"status":           "info",
+		"service":          "orders",
+		"version":          "v1.0.0",
+		"http.method":      "POST",
+		"http.url":         "/orders",
+		"http.status_code": 200,
+		"duration":         29000000,
+		"dd.trace_id":      "10101258189943802584",
+		"dd.span_id":       "16499503129482223124",
+	})
+}
+
+func TestSchemaKeys(t *testing.T) {
+	record := logSchemaTDR(t, logger.SchemaOption{Keys: map[string]string{
+		"message":  "msg",
+		"app_name": "service",
+	}})
+
+	assertRecord(t, record, map[string]interface{}{
+		"msg":     "TDR",
+		"service": "orders",
+	})
+
+	if _, ok := record["app_name"]; ok {
+		t.Errorf("app_name is not renamed: %v", record)
+	}
+}
@@ -0,0 +1,240 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newConsoleWriter returns a writer to stdout or stderr. Syncing a console
+// returns an error on most platforms while writes are unbuffered anyway, so
+// sync is skipped.
+func newConsoleWriter(f *os.File) zapcore.WriteSyncer {
+	return zapcore.Lock(zapcore.AddSync(struct{ io.Writer }{f}))
+}
+
+// newSinkWriter returns the output of sink, along with the resources that
+// must be closed when the logger is no longer used.
+func newSinkWriter(sink SinkOption) (zapcore.WriteSyncer, []io.Closer, error) {
+	switch sink.Output {
+	case "stdout":
+		return newConsoleWriter(os.Stdout), nil, nil
+	case "stderr":
+		return newConsoleWriter(os.Stderr), nil, nil
+	case "file":
+		if sink.File.Path == "" {
+			return nil, nil, errors.New("file sink without File.Path")
+		}
+
+		file := newRotatingFile(sink.File)
+		return file, []io.Closer{file}, nil
+	}
+
+	u, err := url.Parse(sink.Output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch u.Scheme {
+	case "unix", "unixgram":
+		socket := &socketWriter{network: u.Scheme, address: u.Path}
+		return socket, []io.Closer{socket}, nil
+	case "tcp", "udp":
+		socket := &socketWriter{network: u.Scheme, address: u.Host}
+		return socket, []io.Closer{socket}, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported output %q", sink.Output)
+}
+
+// newSinkCore creates the core writing to output with the encoding, schema
+// and masking of sink. The async writer wrapping output, if any, is returned
+// to be closed before output.
+func newSinkCore(opt Option, sink SinkOption, output zapcore.WriteSyncer, level zapcore.LevelEnabler, color bool) (zapcore.Core, []io.Closer) {
+	var closers []io.Closer
+
+	// Async writer must be closed before the outputs it writes to
+	if opt.Async.Enable {
+		async := newAsyncWriter(output, opt.Async)
+		output = async
+		closers = append(closers, async)
+	}
+
+	encoderConfig := getEncoderConfig()
+	applySchema(&encoderConfig, sink.Schema)
+
+	core := zapcore.NewCore(
+		newEncoder(sink.Encoding, encoderConfig, color),
+		output,
+		level,
+	)
+
+	if opt.StackTraceFormat == StackTraceArray {
+		core = &stackArrayCore{Core: core, key: encoderConfig.StacktraceKey}
+	}
+	core = newSchemaCore(core, sink.Schema)
+
+	if len(sink.MaskingFields) > 0 || len(sink.MaskingRules) > 0 || len(sink.MaskingDetectors) > 0 {
+		core = &maskingCore{Core: core, masker: newMasker(Option{
+			EnableMaskingFields:    true,
+			MaskingFields:          sink.MaskingFields,
+			MaskingRules:           sink.MaskingRules,
+			MaskingDetectors:       sink.MaskingDetectors,
+			MaskingCaseInsensitive: opt.MaskingCaseInsensitive,
+			MaskingHashSalt:        opt.MaskingHashSalt,
+			Proto:                  opt.Proto,
+		})}
+	}
+
+	return core, closers
+}
+
+// maskingCore masks the fields of records written to a single sink, on top
+// of the masking done by the logger.
+type maskingCore struct {
+	zapcore.Core
+	masker *masker
+}
+
+func (c *maskingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &maskingCore{Core: c.Core.With(c.mask(fields)), masker: c.masker}
+}
+
+func (c *maskingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *maskingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.mask(fields))
+}
+
+// mask returns a masked copy of fields. Keys of the fields are matched as
+// top level keys of the record.
+func (c *maskingCore) mask(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		if field.Type == zapcore.SkipType {
+			out[i] = field
+			continue
+		}
+
+		masked := c.masker.mask(map[string]interface{}{field.Key: fieldValue(field)})
+		out[i] = zap.Any(field.Key, masked.(map[string]interface{})[field.Key])
+	}
+
+	return out
+}
+
+const (
+	socketDialTimeout  = time.Second
+	socketWriteTimeout = time.Second
+	socketMinBackoff   = 100 * time.Millisecond
+	socketMaxBackoff   = 30 * time.Second
+)
+
+// socketWriter writes records to a socket, connecting on the first write and
+// again after a failed write. Failed connections are retried with an
+// exponential backoff, records written in the meantime are dropped, so a
+// collector going down does not stall the callers. Only the first failure of
+// an outage is returned, for zap to report it once.
+type socketWriter struct {
+	network string
+	address string
+	dropped atomic.Uint64
+
+	mu      sync.Mutex
+	conn    net.Conn
+	backoff time.Duration
+	retryAt time.Time
+	// down reports whether the current outage was reported
+	down bool
+}
+
+func (w *socketWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+
+	if w.conn == nil {
+		if now.Before(w.retryAt) {
+			return w.drop(p, nil)
+		}
+
+		conn, err := net.DialTimeout(w.network, w.address, socketDialTimeout)
+		if err != nil {
+			w.backoff = min(max(2*w.backoff, socketMinBackoff), socketMaxBackoff)
+			w.retryAt = now.Add(w.backoff)
+
+			return w.drop(p, err)
+		}
+
+		w.conn = conn
+		w.backoff = 0
+		w.down = false
+	}
+
+	w.conn.SetWriteDeadline(now.Add(socketWriteTimeout))
+
+	if _, err := w.conn.Write(p); err != nil {
+		w.conn.Close()
+		w.conn = nil
+
+		return w.drop(p, err)
+	}
+
+	return len(p), nil
+}
+
+// drop counts the records of p as dropped. The error starting an outage is
+// returned, the records dropped afterwards are dropped silently until the
+// socket reconnects.
+func (w *socketWriter) drop(p []byte, err error) (int, error) {
+	// Encoders end every record with a line ending, async batches hold several
+	w.dropped.Add(uint64(max(bytes.Count(p, []byte{'\n'}), 1)))
+
+	if err == nil || w.down {
+		return len(p), nil
+	}
+
+	w.down = true
+	return 0, fmt.Errorf("%s socket %s is down, dropping records until it reconnects: %w", w.network, w.address, err)
+}
+
+// Dropped returns the number of records dropped while the socket was down.
+func (w *socketWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Sync is a no-op, records are written to the socket unbuffered.
+func (w *socketWriter) Sync() error {
+	return nil
+}
+
+func (w *socketWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+
+	return err
+}
@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSocketWriterReconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w := &socketWriter{network: "tcp", address: addr}
+	defer w.Close()
+
+	// Collector is down: the first write reports the outage, the next ones
+	// are dropped silently while backing off
+	var opErr *net.OpError
+	if _, err := w.Write([]byte("dropped\n")); !errors.As(err, &opErr) {
+		t.Fatalf("Write = %v, want the dial error", err)
+	}
+	for range 3 {
+		if n, err := w.Write([]byte("dropped\n")); err != nil || n != len("dropped\n") {
+			t.Fatalf("Write = %d, %v while backing off, want the record dropped silently", n, err)
+		}
+	}
+	if w.backoff != socketMinBackoff {
+		t.Errorf("backoff = %v, want %v", w.backoff, socketMinBackoff)
+	}
+	if got := w.Dropped(); got != 4 {
+		t.Errorf("Dropped = %d, want 4", got)
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("listen again on %s: %v", addr, err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	// Collector is back once the backoff elapsed
+	time.Sleep(socketMinBackoff)
+	if _, err := w.Write([]byte("delivered\n")); err != nil {
+		t.Fatalf("Write after backoff: %v", err)
+	}
+
+	select {
+	case line := <-received:
+		if line != "delivered\n" {
+			t.Errorf("received %q, want the delivered record", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("record was not delivered")
+	}
+
+	if w.backoff != 0 {
+		t.Errorf("backoff = %v after reconnecting, want 0", w.backoff)
+	}
+
+	// A new outage is reported again
+	ln.Close()
+	w.Close()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := w.Write([]byte("dropped\n")); err == nil {
+		t.Error("Write succeeded, want the new outage reported")
+	}
+}
+
+func TestSocketSinkDroppedRecords(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	l := NewLogger(Option{Sinks: []SinkOption{{Output: "tcp://" + addr}}})
+	defer l.Close()
+
+	// A batch written during the outage counts each of its records
+	socket := l.(*defaultLogger).closers[0].(*socketWriter)
+	socket.Write([]byte("{}\n"))
+	socket.Write([]byte("{}\n{}\n"))
+
+	for range 3 {
+		l.Info(context.Background(), "dropped")
+	}
+
+	if got := DroppedRecords(l); got != 6 {
+		t.Errorf("DroppedRecords = %d, want 6", got)
+	}
+}
+
+func TestSinksLevelAndMasking(t *testing.T) {
+	dir := t.TempDir()
+	audit := filepath.Join(dir, "audit.log")
+	errs := filepath.Join(dir, "errors.log")
+
+	l := NewLogger(Option{
+		EnableMaskingFields: true,
+		MaskingFields:       []string{"password"},
+		Sinks: []SinkOption{
+			{Output: "file", File: FileOption{Path: audit}, Level: "debug"},
+			{Output: "file", File: FileOption{Path: errs}, Level: "error", MaskingFields: []string{"email"}},
+		},
+	})
+
+	ctx := context.Background()
+	l.Debug(ctx, "signup", F("email", "jane@example.com"), F("password", "hunter2"))
+	l.Error(ctx, "signup failed", F("email", "jane@example.com"), F("password", "hunter2"))
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	read := func(path string) []map[string]interface{} {
+		t.Helper()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+
+		var records []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			record := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("decode %s: %v", line, err)
+			}
+			records = append(records, record)
+		}
+
+		return records
+	}
+
+	// The audit sink gets every record, masked by the Option only
+	auditRecords := read(audit)
+	if len(auditRecords) != 2 {
+		t.Fatalf("audit records = %d, want 2", len(auditRecords))
+	}
+	for _, record := range auditRecords {
+		if record["email"] != "jane@example.com" || record["password"] != maskedValue {
+			t.Errorf("audit record email = %v password = %v, want the email kept and the password masked", record["email"], record["password"])
+		}
+	}
+
+	// The error sink gets errors only, with its own masking on top
+	errRecords := read(errs)
+	if len(errRecords) != 1 || errRecords[0]["message"] != "signup failed" {
+		t.Fatalf("error records = %v, want the error record only", errRecords)
+	}
+	if errRecords[0]["email"] != maskedValue || errRecords[0]["password"] != maskedValue {
+		t.Errorf("error record email = %v password = %v, want both masked", errRecords[0]["email"], errRecords[0]["password"])
+	}
+}
@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler is a slog.Handler writing through a Logger, so records logged
+// with log/slog get the same shape (app_* fields, trace.id/span.id, masking).
+type slogHandler struct {
+	logger Logger
+	attrs  []interface{}
+	group  string
+}
+
+// NewSlogHandler returns a slog.Handler writing through l. Attributes become
+// named fields, groups are flattened into dot separated keys.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= toSlogLevel(h.logger.ComponentLevel(componentFromCtx(ctx)))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	details := make([]interface{}, 0, len(h.attrs)+r.NumAttrs())
+	details = append(details, h.attrs...)
+
+	r.Attrs(func(attr slog.Attr) bool {
+		details = appendSlogAttr(details, h.group, attr)
+		return true
+	})
+
+	// The caller is the slog call site, found at r.PC
+	if d, ok := unwrapLogger(h.logger); ok {
+		d.logAt(ctx, fromSlogLevel(r.Level), r.PC, r.Message, details...)
+		return nil
+	}
+
+	switch fromSlogLevel(r.Level) {
+	case zapcore.DebugLevel:
+		h.logger.Debug(ctx, r.Message, details...)
+	case zapcore.InfoLevel:
+		h.logger.Info(ctx, r.Message, details...)
+	case zapcore.WarnLevel:
+		h.logger.Warn(ctx, r.Message, details...)
+	default:
+		h.logger.Error(ctx, r.Message, details...)
+	}
+
+	return nil
+}
+
+// unwrapLogger returns the defaultLogger behind l, if any.
+func unwrapLogger(l Logger) (*defaultLogger, bool) {
+	if tl, ok := l.(*TestLogger); ok {
+		l = tl.Logger
+	}
+
+	d, ok := l.(*defaultLogger)
+	return d, ok
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := *h
+	child.attrs = make([]interface{}, 0, len(h.attrs)+len(attrs))
+	child.attrs = append(child.attrs, h.attrs...)
+
+	for _, attr := range attrs {
+		child.attrs = appendSlogAttr(child.attrs, h.group, attr)
+	}
+
+	return &child
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	child := *h
+	child.group = h.group + name + "."
+
+	return &child
+}
+
+// appendSlogAttr converts attr to a named field prefixed by group.
+func appendSlogAttr(details []interface{}, group string, attr slog.Attr) []interface{} {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return details
+	}
+
+	// Group without key is inlined into its parent
+	if attr.Key == "" && attr.Value.Kind() == slog.KindGroup {
+		for _, child := range attr.Value.Group() {
+			details = appendSlogAttr(details, group, child)
+		}
+
+		return details
+	}
+
+	return append(details, F(group+attr.Key, slogValue(attr.Value)))
+}
+
+// slogValue converts a slog value to a value formatLog understands.
+func slogValue(val slog.Value) interface{} {
+	val = val.Resolve()
+
+	switch val.Kind() {
+	case slog.KindGroup:
+		data := make(map[string]interface{})
+		for _, attr := range val.Group() {
+			data[attr.Key] = slogValue(attr.Value)
+		}
+
+		return data
+	case slog.KindTime:
+		return val.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		return val.Duration().String()
+	default:
+		return val.Any()
+	}
+}
+
+// NewSlogLogger creates a Logger writing through h. Records keep the same
+// fields as NewLogger, including masking and trace context, and are handed
+// to h as attributes.
+func NewSlogLogger(h slog.Handler, opt Option) Logger {
+	logger := newDefaultLogger(opt, zap.New(&slogCore{handler: h}, zap.WithCaller(opt.EnableCaller)))
+
+	// Assign to global variable, so it can be called in all file without injecting depedency
+	Log = logger
+
+	return logger
+}
+
+// slogCore is a zapcore.Core writing entries to a slog.Handler.
+type slogCore struct {
+	handler slog.Handler
+	fields  []zapcore.Field
+}
+
+func (c *slogCore) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), toSlogLevel(level))
+}
+
+func (c *slogCore) With(fields []zapcore.Field) zapcore.Core {
+	child := &slogCore{handler: c.handler}
+	child.fields = make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	child.fields = append(child.fields, c.fields...)
+	child.fields = append(child.fields, fields...)
+
+	return child
+}
+
+func (c *slogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *slogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	r := slog.NewRecord(ent.Time, toSlogLevel(ent.Level), ent.Message, ent.Caller.PC)
+
+	if ent.LoggerName != "" {
+		r.AddAttrs(slog.String("logger", ent.LoggerName))
+	}
+
+	addSlogAttrs(&r, c.fields)
+	addSlogAttrs(&r, fields)
+
+	if ent.Stack != "" {
+		r.AddAttrs(slog.String("stacktrace", ent.Stack))
+	}
+
+	return c.handler.Handle(context.Background(), r)
+}
+
+// addSlogAttrs adds zap fields to the record, keeping their order.
+func addSlogAttrs(r *slog.Record, fields []zapcore.Field) {
+	for _, field := range fields {
+		if field.Type == zapcore.SkipType {
+			continue
+		}
+
+		// Every field adds its value under its own key
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		r.AddAttrs(slog.Any(field.Key, enc.Fields[field.Key]))
+	}
+}
+
+func (c *slogCore) Sync() error {
+	return nil
+}
+
+// fromSlogLevel maps a slog level to the zap level below or at it, levels
+// above error are logged as error.
+func fromSlogLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// toSlogLevel maps a zap level to the closest slog level.
+func toSlogLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	case level == zapcore.ErrorLevel:
+		return slog.LevelError
+	default:
+		// DPanic, Panic and Fatal are above error
+		return slog.LevelError + slog.Level(level-zapcore.ErrorLevel)*4
+	}
+}
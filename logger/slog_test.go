@@ -0,0 +1,136 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSlogHandlerCaller(t *testing.T) {
+	var line int
+	records := fileRecords(t, logger.Option{EnableCaller: true}, func(l logger.Logger) {
+		_, _, line, _ = runtime.Caller(0)
+		slog.New(logger.NewSlogHandler(l)).Info("charged")
+	})
+
+	want := fmt.Sprintf("logger/slog_test.go:%d", line+1)
+	if got := records[0]["caller"]; got != want {
+		t.Errorf("caller = %v, want %v", got, want)
+	}
+	if got, _ := records[0]["function"].(string); !strings.Contains(got, "TestSlogHandlerCaller") {
+		t.Errorf("function = %v, want the slog call site", got)
+	}
+}
+
+func TestSlogHandlerGroups(t *testing.T) {
+	tl := logger.NewTestLogger()
+
+	slog.New(logger.NewSlogHandler(tl)).
+		With("tenant", "acme").
+		WithGroup("req").
+		Info("charged", slog.Int("status", 200), slog.Group("user", "id", 7), slog.Group("", "inline", true))
+
+	entry := tl.Entries()[0]
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{path: "tenant", want: "acme"},
+		{path: "req.status", want: float64(200)},
+		{path: "req.user.id", want: float64(7)},
+		{path: "req.inline", want: true},
+	}
+
+	for _, tt := range tests {
+		if got, _ := entry.Lookup(tt.path); got != tt.want {
+			t.Errorf("%s = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+	tl := logger.NewTestLogger()
+	log := slog.New(logger.NewSlogHandler(tl))
+
+	tests := []struct {
+		level slog.Level
+		want  zapcore.Level
+	}{
+		{level: slog.LevelDebug + 2, want: zapcore.DebugLevel},
+		{level: slog.LevelDebug, want: zapcore.DebugLevel},
+		{level: slog.LevelInfo, want: zapcore.InfoLevel},
+		{level: slog.LevelInfo + 2, want: zapcore.InfoLevel},
+		{level: slog.LevelWarn, want: zapcore.WarnLevel},
+		{level: slog.LevelError, want: zapcore.ErrorLevel},
+		{level: slog.LevelError + 4, want: zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		tl.Reset()
+		log.Log(context.Background(), tt.level, "charged")
+
+		entries := tl.Entries()
+		if len(entries) != 1 || entries[0].Level != tt.want {
+			t.Errorf("slog level %v logged as %v, want %v", tt.level, entries, tt.want)
+		}
+	}
+
+	// Records below the level of the logger are not handled
+	tl.SetLevel(zapcore.WarnLevel)
+	tl.Reset()
+	log.Info("dropped")
+	if got := tl.Entries(); len(got) != 0 {
+		t.Errorf("records = %d below the logger level, want 0", len(got))
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewSlogLogger(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), logger.Option{
+		IsEnable:            true,
+		Level:               "debug",
+		EnableMaskingFields: true,
+		MaskingFields:       []string{"password"},
+	})
+
+	ctx := context.Background()
+	l.Debug(ctx, "debug")
+	l.Info(ctx, "login", logger.F("user", "alice"), logger.F("password", "hunter2"))
+	l.Warn(ctx, "warn")
+	l.Error(ctx, "error")
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decode %s: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("records = %d, want 4: %s", len(records), buf.String())
+	}
+
+	for i, want := range []string{"DEBUG", "INFO", "WARN", "ERROR"} {
+		if got := records[i]["level"]; got != want {
+			t.Errorf("record %d level = %v, want %v", i, got, want)
+		}
+	}
+
+	login := records[1]
+	if login["msg"] != "login" || login["user"] != "alice" || login["app_name"] == nil {
+		t.Errorf("login record = %v, want the message, fields and app_* fields", login)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("password is not masked: %v", login["password"])
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// addSpanEvent records the log as an event on the active span. Fields are
+// expected to be masked already.
+func (d *defaultLogger) addSpanEvent(ctx context.Context, level zapcore.Level, message string, fields []zap.Field) {
+	if !d.enableSpanEvents || level < d.spanEventLevel {
+		return
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", level.String()),
+	}
+	attrs = append(attrs, fieldsToAttributes(fields)...)
+
+	span.AddEvent(message, trace.WithAttributes(attrs...))
+
+	if level >= zapcore.ErrorLevel {
+		span.SetStatus(codes.Error, message)
+	}
+}
+
+// fieldsToAttributes converts zap fields to span attributes, complex values
+// are encoded as JSON strings.
+func fieldsToAttributes(fields []zap.Field) []attribute.KeyValue {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for key := range enc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		switch v := enc.Fields[key].(type) {
+		case string:
+			attrs = append(attrs, attribute.String(key, v))
+		case bool:
+			attrs = append(attrs, attribute.Bool(key, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(key, v))
+		case int:
+			attrs = append(attrs, attribute.Int(key, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(key, v))
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				attrs = append(attrs, attribute.String(key, fmt.Sprint(v)))
+				continue
+			}
+
+			attrs = append(attrs, attribute.String(key, string(b)))
+		}
+	}
+
+	return attrs
+}
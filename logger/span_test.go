@@ -0,0 +1,102 @@
+package logger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttribute(attrs []attribute.KeyValue, key string) (attribute.Value, bool) {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return attr.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
+
+func TestSpanEvents(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	logger.NewTestLogger(logger.Option{
+		IsEnable:            true,
+		EnableSpanEvents:    true,
+		SpanEventLevel:      "warn",
+		EnableMaskingFields: true,
+		MaskingFields:       []string{"password"},
+	})
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "charge")
+
+	logger.Log.Info(ctx, "below the span event level")
+	logger.Log.Warn(ctx, "retrying charge", logger.F("card", map[string]string{"password": "hunter2"}), logger.F("attempt", 2))
+	logger.Log.Error(ctx, "charge failed")
+
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+
+	events := spans[0].Events()
+	if len(events) != 2 {
+		t.Fatalf("span events = %d, want the warn and error records only", len(events))
+	}
+
+	warn := events[0]
+	if warn.Name != "retrying charge" {
+		t.Errorf("event name = %q, want %q", warn.Name, "retrying charge")
+	}
+	if severity, _ := spanAttribute(warn.Attributes, "log.severity"); severity.AsString() != "warn" {
+		t.Errorf("log.severity = %q, want warn", severity.AsString())
+	}
+	if attempt, ok := spanAttribute(warn.Attributes, "attempt"); !ok || attempt.AsInt64() != 2 {
+		t.Errorf("attempt = %v, want 2", attempt.Emit())
+	}
+
+	card, ok := spanAttribute(warn.Attributes, "card")
+	if !ok {
+		t.Fatal("card attribute is missing")
+	}
+	if got := card.AsString(); strings.Contains(got, "hunter2") || !strings.Contains(got, `"password":"*`) {
+		t.Errorf("card attribute = %s, want the password masked", got)
+	}
+
+	if events[1].Name != "charge failed" {
+		t.Errorf("event name = %q, want %q", events[1].Name, "charge failed")
+	}
+
+	status := spans[0].Status()
+	if status.Code != codes.Error || status.Description != "charge failed" {
+		t.Errorf("span status = %v %q, want Error %q", status.Code, status.Description, "charge failed")
+	}
+}
+
+func TestSpanEventsDisabled(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	logger.NewTestLogger()
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "charge")
+	logger.Log.Error(ctx, "charge failed")
+	span.End()
+
+	if events := recorder.Ended()[0].Events(); len(events) != 0 {
+		t.Errorf("span events = %d with EnableSpanEvents unset, want 0", len(events))
+	}
+	if status := recorder.Ended()[0].Status(); status.Code != codes.Unset {
+		t.Errorf("span status = %v with EnableSpanEvents unset, want Unset", status.Code)
+	}
+}
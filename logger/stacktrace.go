@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// stackFrame is a frame of a stack trace written with StackTraceArray.
+type stackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// stackArrayCore writes the stack trace of entries as an array of frames
+// instead of a single string.
+type stackArrayCore struct {
+	zapcore.Core
+	key string
+}
+
+func (c *stackArrayCore) With(fields []zapcore.Field) zapcore.Core {
+	return &stackArrayCore{Core: c.Core.With(fields), key: c.key}
+}
+
+func (c *stackArrayCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *stackArrayCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Stack != "" {
+		fields = append(fields, zap.Any(c.key, parseStack(ent.Stack)))
+		ent.Stack = ""
+	}
+
+	return c.Core.Write(ent, fields)
+}
+
+// parseStack parses a stack trace formatted by zap, a function line followed
+// by a tab indented file:line line for every frame.
+func parseStack(stack string) []stackFrame {
+	lines := strings.Split(stack, "\n")
+
+	frames := make([]stackFrame, 0, len(lines)/2)
+	for i := 0; i+1 < len(lines); i += 2 {
+		frame := stackFrame{Function: lines[i]}
+
+		location := strings.TrimSpace(lines[i+1])
+		if colon := strings.LastIndexByte(location, ':'); colon >= 0 {
+			frame.File = location[:colon]
+			frame.Line, _ = strconv.Atoi(location[colon+1:])
+		} else {
+			frame.File = location
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
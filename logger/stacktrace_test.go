@@ -0,0 +1,102 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/insaneadinesia/gobang/logger"
+)
+
+// line returns the line following its call.
+func line() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line + 1
+}
+
+func TestCallerSkip(t *testing.T) {
+	ctx := logger.InjectCtx(context.Background(), logger.Context{ServiceName: "orders"})
+	var want []string
+
+	records := fileRecords(t, logger.Option{EnableCaller: true}, func(l logger.Logger) {
+		want = append(want, fmt.Sprintf("logger/stacktrace_test.go:%d", line()))
+		l.Info(ctx, "info")
+
+		want = append(want, fmt.Sprintf("logger/stacktrace_test.go:%d", line()))
+		l.Error(ctx, "error")
+
+		want = append(want, fmt.Sprintf("logger/stacktrace_test.go:%d", line()))
+		l.TDR(ctx)
+
+		child := l.Named("payment").With(logger.F("job_id", "j-1"))
+		want = append(want, fmt.Sprintf("logger/stacktrace_test.go:%d", line()))
+		child.Named("refund").Warn(ctx, "child")
+
+		// The TDR of the middleware is logged from the middleware itself
+		mw := logger.NewHTTPMiddleware(logger.HTTPMiddlewareOption{})
+		mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+			ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+		want = append(want, "logger/http.go:")
+	})
+
+	if len(records) != len(want) {
+		t.Fatalf("records = %d, want %d", len(records), len(want))
+	}
+
+	for i, record := range records {
+		caller, _ := record["caller"].(string)
+		if !strings.HasPrefix(caller, want[i]) {
+			t.Errorf("%v caller = %q, want %q", record["message"], caller, want[i])
+		}
+	}
+}
+
+func TestStackTraceArray(t *testing.T) {
+	records := fileRecords(t, logger.Option{
+		EnableStackTrace: true,
+		StackTraceLevel:  "error",
+		StackTraceFormat: logger.StackTraceArray,
+	}, func(l logger.Logger) {
+		l.Warn(context.Background(), "below the stack trace level")
+		l.Error(context.Background(), "charge failed")
+	})
+
+	if len(records) != 2 {
+		t.Fatalf("records = %d, want 2", len(records))
+	}
+	if stack, ok := records[0]["stacktrace"]; ok {
+		t.Errorf("warn record has a stack trace: %v", stack)
+	}
+
+	frames, ok := records[1]["stacktrace"].([]interface{})
+	if !ok || len(frames) == 0 {
+		t.Fatalf("stacktrace = %#v, want an array of frames", records[1]["stacktrace"])
+	}
+
+	// The first frame is the caller, not the logger
+	first, _ := frames[0].(map[string]interface{})
+	if function, _ := first["function"].(string); !strings.HasSuffix(function, ".TestStackTraceArray.func1") {
+		t.Errorf("first frame function = %v, want the caller", first["function"])
+	}
+	if file, _ := first["file"].(string); !strings.HasSuffix(file, "logger/stacktrace_test.go") {
+		t.Errorf("first frame file = %v, want the caller", first["file"])
+	}
+	if line, _ := first["line"].(float64); line <= 0 {
+		t.Errorf("first frame line = %v, want a line number", first["line"])
+	}
+}
+
+func TestStackTraceString(t *testing.T) {
+	records := fileRecords(t, logger.Option{EnableStackTrace: true}, func(l logger.Logger) {
+		l.Warn(context.Background(), "retrying charge")
+	})
+
+	stack, _ := records[0]["stacktrace"].(string)
+	if !strings.HasPrefix(stack, "github.com/insaneadinesia/gobang/logger_test.TestStackTraceString.func1\n") {
+		t.Errorf("stacktrace = %q, want it to start at the caller", stack)
+	}
+}
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestLogger is a Logger keeping its records in memory, so tests can assert
+// on them instead of capturing stdout.
+type TestLogger struct {
+	Logger
+	logs *observer.ObservedLogs
+}
+
+// Entry is a record written to a TestLogger. Fields hold the record as it is
+// encoded to JSON, including trace.id, span.id and the app_* fields.
+type Entry struct {
+	Level   zapcore.Level
+	Time    time.Time
+	Message string
+	Fields  map[string]interface{}
+}
+
+// Entries is a list of records that can be filtered.
+type Entries []Entry
+
+// NewTestLogger creates a TestLogger and assigns it to the global Log, like
+// NewLogger does. The optional opt configures masking and levels, the level
+// defaults to debug.
+func NewTestLogger(opt ...Option) *TestLogger {
+	o := Option{IsEnable: true}
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	if o.Level == "" {
+		o.Level = zapcore.DebugLevel.String()
+	}
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := &TestLogger{
+		Logger: newDefaultLogger(o, zap.New(core)),
+		logs:   logs,
+	}
+
+	Log = logger
+
+	return logger
+}
+
+// Entries returns every record written so far.
+func (l *TestLogger) Entries() Entries {
+	logged := l.logs.All()
+
+	entries := make(Entries, 0, len(logged))
+	for _, e := range logged {
+		entries = append(entries, Entry{
+			Level:   e.Level,
+			Time:    e.Time,
+			Message: e.Message,
+			Fields:  normalize(e.ContextMap()).(map[string]interface{}),
+		})
+	}
+
+	return entries
+}
+
+// TDRs returns the TDR records written so far.
+func (l *TestLogger) TDRs() Entries {
+	return l.Entries().FilterMessage(tdrMessage)
+}
+
+// Reset removes the records written so far.
+func (l *TestLogger) Reset() {
+	l.logs.TakeAll()
+}
+
+// FilterLevel returns the records written at level.
+func (e Entries) FilterLevel(level zapcore.Level) Entries {
+	return e.filter(func(entry Entry) bool {
+		return entry.Level == level
+	})
+}
+
+// FilterMessage returns the records with the given message.
+func (e Entries) FilterMessage(message string) Entries {
+	return e.filter(func(entry Entry) bool {
+		return entry.Message == message
+	})
+}
+
+// FilterMessageSnippet returns the records whose message contains snippet.
+func (e Entries) FilterMessageSnippet(snippet string) Entries {
+	return e.filter(func(entry Entry) bool {
+		return strings.Contains(entry.Message, snippet)
+	})
+}
+
+// FilterField returns the records having val at path, see Entry.Lookup.
+func (e Entries) FilterField(path string, val interface{}) Entries {
+	want := normalize(val)
+
+	return e.filter(func(entry Entry) bool {
+		got, ok := entry.Lookup(path)
+		return ok && reflect.DeepEqual(got, want)
+	})
+}
+
+func (e Entries) filter(fn func(Entry) bool) Entries {
+	var filtered Entries
+	for _, entry := range e {
+		if fn(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered
+}
+
+// Lookup returns the value at a dot separated path of the record, such as
+// "app_request.customer.password". Keys containing dots are matched first,
+// so "trace.id" works as well.
+func (e Entry) Lookup(path string) (interface{}, bool) {
+	return lookup(e.Fields, path)
+}
+
+func lookup(data map[string]interface{}, path string) (interface{}, bool) {
+	if val, ok := data[path]; ok {
+		return val, true
+	}
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' {
+			continue
+		}
+
+		nested, ok := data[path[:i]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if val, ok := lookup(nested, path[i+1:]); ok {
+			return val, true
+		}
+	}
+
+	return nil, false
+}
+
+// normalize converts val to the value it has once encoded to JSON and
+// decoded back, so fields compare the same whatever type they were logged as.
+func normalize(val interface{}) interface{} {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return val
+	}
+
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return val
+	}
+
+	return out
+}